@@ -0,0 +1,528 @@
+// Package objects provides Encore applications with a portable API for
+// storing and retrieving unstructured data in a cloud object storage bucket
+// (for example Google Cloud Storage or Amazon S3).
+//
+// See https://encore.dev/docs/primitives/object-storage for more information.
+package objects
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"time"
+
+	"encore.dev/storage/objects/internal/types"
+)
+
+// Bucket represents an object storage bucket, storing unstructured data.
+//
+// See NewBucket for more information on how to declare a Bucket.
+type Bucket struct {
+	name string
+	impl types.BucketImpl
+}
+
+// BucketConfig is the configuration for a Bucket.
+type BucketConfig struct {
+	// Versioned specifies whether the bucket supports multiple versions of each object.
+	Versioned bool
+}
+
+// NewBucket declares a new object storage bucket with the given name and configuration.
+//
+// The name must be unique within the Encore application, as it's used to
+// identify the bucket both at compile time and at runtime.
+//
+// NewBucket must be called from within a package level variable declaration,
+// as Encore's static analysis needs to see the declaration at compile time.
+func NewBucket(name string, cfg BucketConfig) *Bucket {
+	impl := getImpl(name)
+	return &Bucket{name: name, impl: impl}
+}
+
+// CloudObject is the name of an object within a bucket.
+type CloudObject = types.CloudObject
+
+// ObjectAttrs describes the attributes of an object in a bucket.
+type ObjectAttrs struct {
+	// Object is the name of the object.
+	Object string
+	// Version is the version of the object, if the bucket is versioned.
+	Version string
+	// ContentType is the content type of the object, if set.
+	ContentType string
+	// Size is the size of the object, in bytes.
+	Size int64
+	// ETag is the object's HTTP entity tag.
+	ETag string
+
+	// Metadata is the user-provided metadata associated with the object.
+	Metadata map[string]string
+	// CacheControl is the Cache-Control header the object is served with.
+	CacheControl string
+	// ContentEncoding is the Content-Encoding header the object is served with.
+	ContentEncoding string
+	// ContentDisposition is the Content-Disposition header the object is served with.
+	ContentDisposition string
+	// ContentLanguage is the Content-Language header the object is served with.
+	ContentLanguage string
+	// CRC32C is the CRC32C checksum (using the Castagnoli polynomial) of the object's content.
+	CRC32C *uint32
+	// MD5 is the MD5 digest of the object's content.
+	MD5 []byte
+	// StorageClass is the storage class the object is stored with.
+	StorageClass string
+}
+
+// ListEntry describes a single object returned from a List operation.
+type ListEntry struct {
+	// Object is the name of the object.
+	Object string
+	// Version is the version of the object, if the bucket is versioned.
+	Version string
+	// Size is the size of the object, in bytes.
+	Size int64
+	// ETag is the object's HTTP entity tag.
+	ETag string
+	// IsLatest reports whether this is the current version of the object.
+	// Only meaningful when the List operation was called with WithAllVersions.
+	IsLatest bool
+	// DeletedAt is set if this version of the object has been deleted,
+	// i.e. it's a noncurrent version retained by a versioned bucket.
+	DeletedAt *time.Time
+
+	// Metadata is the user-provided metadata associated with the object.
+	Metadata map[string]string
+	// CacheControl is the Cache-Control header the object is served with.
+	CacheControl string
+	// ContentEncoding is the Content-Encoding header the object is served with.
+	ContentEncoding string
+	// ContentDisposition is the Content-Disposition header the object is served with.
+	ContentDisposition string
+	// ContentLanguage is the Content-Language header the object is served with.
+	ContentLanguage string
+	// CRC32C is the CRC32C checksum (using the Castagnoli polynomial) of the object's content.
+	CRC32C *uint32
+	// MD5 is the MD5 digest of the object's content.
+	MD5 []byte
+	// StorageClass is the storage class the object is stored with.
+	StorageClass string
+}
+
+// Download opens the named object for reading.
+//
+// The returned reader must be closed once it's no longer needed.
+func (b *Bucket) Download(ctx context.Context, object string, opts ...DownloadOption) (io.ReadCloser, error) {
+	var options downloadOptions
+	for _, opt := range opts {
+		opt.applyDownload(&options)
+	}
+
+	r, err := b.impl.Download(types.DownloadData{
+		Ctx:           ctx,
+		Object:        types.CloudObject(object),
+		Version:       options.version,
+		Pre:           mapPreconditions(options.pre),
+		EncryptionKey: options.encryptionKey,
+	})
+	return r, mapErr(err)
+}
+
+// Upload returns a writer that uploads the named object.
+//
+// The caller must call Complete (or Abort on error) once done writing.
+func (b *Bucket) Upload(ctx context.Context, object string, opts ...UploadOption) *Writer {
+	var options uploadOptions
+	for _, opt := range opts {
+		opt.applyUpload(&options)
+	}
+
+	u, err := b.impl.Upload(types.UploadData{
+		Ctx:        ctx,
+		Object:     types.CloudObject(object),
+		Attrs:      options.attrs,
+		Pre:        mapPreconditions(options.pre),
+		SendCRC32C: options.sendCRC32C,
+	})
+	return &Writer{u: u, err: mapErr(err)}
+}
+
+// Writer writes data to an object being uploaded to a Bucket.
+//
+//publicapigen:keep
+type Writer struct {
+	u   types.Uploader
+	err error
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.u.Write(p)
+	return n, mapErr(err)
+}
+
+// Complete completes the upload and returns the resulting object's attributes.
+func (w *Writer) Complete() (*ObjectAttrs, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	attrs, err := w.u.Complete()
+	return mapAttrs(attrs), mapErr(err)
+}
+
+// Abort aborts the upload, discarding any data written so far.
+func (w *Writer) Abort(err error) {
+	if w.u != nil {
+		w.u.Abort(err)
+	}
+}
+
+// Remove removes an object from the bucket.
+func (b *Bucket) Remove(ctx context.Context, object string, opts ...RemoveOption) error {
+	var options removeOptions
+	for _, opt := range opts {
+		opt.applyRemove(&options)
+	}
+
+	return mapErr(b.impl.Remove(types.RemoveData{
+		Ctx:     ctx,
+		Object:  types.CloudObject(object),
+		Version: options.version,
+		Pre:     mapPreconditions(options.pre),
+	}))
+}
+
+// Attrs returns the attributes of an object.
+func (b *Bucket) Attrs(ctx context.Context, object string, opts ...AttrsOption) (*ObjectAttrs, error) {
+	var options attrsOptions
+	for _, opt := range opts {
+		opt.applyAttrs(&options)
+	}
+
+	attrs, err := b.impl.Attrs(types.AttrsData{
+		Ctx:           ctx,
+		Object:        types.CloudObject(object),
+		Version:       options.version,
+		EncryptionKey: options.encryptionKey,
+	})
+	return mapAttrs(attrs), mapErr(err)
+}
+
+// Exists reports whether an object exists in the bucket.
+func (b *Bucket) Exists(ctx context.Context, object string, opts ...ExistsOption) (bool, error) {
+	var options existsOptions
+	for _, opt := range opts {
+		opt.applyExists(&options)
+	}
+
+	_, err := b.impl.Attrs(types.AttrsData{
+		Ctx:           ctx,
+		Object:        types.CloudObject(object),
+		Version:       options.version,
+		EncryptionKey: options.encryptionKey,
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrObjectNotExist):
+		return false, nil
+	default:
+		return false, mapErr(err)
+	}
+}
+
+// List lists the objects in the bucket, optionally filtered by the given options.
+func (b *Bucket) List(ctx context.Context, prefix string, opts ...ListOption) iter.Seq2[*ListEntry, error] {
+	var options listOptions
+	for _, opt := range opts {
+		opt.applyList(&options)
+	}
+
+	return func(yield func(*ListEntry, error) bool) {
+		data := types.ListData{
+			Ctx:         ctx,
+			Prefix:      prefix,
+			AllVersions: options.allVersions,
+			Delimiter:   options.delimiter,
+			StartOffset: options.startOffset,
+			EndOffset:   options.endOffset,
+		}
+		for entry, err := range b.impl.List(data) {
+			if err != nil {
+				yield(nil, mapErr(err))
+				return
+			}
+			if !yield(mapListEntry(entry), nil) {
+				return
+			}
+		}
+	}
+}
+
+// ListPage describes a single page of results from ListPaged.
+type ListPage struct {
+	// Entries are the objects found on this page.
+	Entries []*ListEntry
+
+	// Prefixes are the common prefixes found on this page, when WithDelimiter was given.
+	Prefixes []string
+
+	// NextPageToken resumes listing after this page, via WithPageToken. Empty
+	// if there are no more pages.
+	NextPageToken string
+}
+
+// ListPaged lists a single page of objects in the bucket, optionally filtered
+// by the given options. Unlike List, it returns a page token that callers can
+// use to resume listing in a later call, making it suitable for paging
+// through results across separate requests (e.g. in an HTTP handler).
+func (b *Bucket) ListPaged(ctx context.Context, prefix string, opts ...ListPageOption) (*ListPage, error) {
+	var options listPageOptions
+	for _, opt := range opts {
+		opt.applyListPage(&options)
+	}
+
+	page, err := b.impl.ListPaged(types.ListData{
+		Ctx:         ctx,
+		Prefix:      prefix,
+		AllVersions: options.allVersions,
+		Delimiter:   options.delimiter,
+		StartOffset: options.startOffset,
+		EndOffset:   options.endOffset,
+		PageToken:   options.pageToken,
+		PageSize:    options.pageSize,
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	entries := make([]*ListEntry, len(page.Entries))
+	for i, entry := range page.Entries {
+		entries[i] = mapListEntry(entry)
+	}
+	return &ListPage{
+		Entries:       entries,
+		Prefixes:      page.Prefixes,
+		NextPageToken: page.NextPageToken,
+	}, nil
+}
+
+// SignedURL is a pre-signed, time-limited URL that grants access to perform
+// a single HTTP operation against an object, without requiring the caller
+// to authenticate with the cloud provider.
+type SignedURL struct {
+	// URL is the signed URL.
+	URL string
+	// ExpiresAt is when the signed URL expires.
+	ExpiresAt time.Time
+}
+
+// SignedDownloadURL generates a signed URL that allows performing an HTTP GET
+// request to download the named object, without requiring further authentication.
+func (b *Bucket) SignedDownloadURL(ctx context.Context, object string, ttl time.Duration, opts ...SignedURLOption) (*SignedURL, error) {
+	return b.signedURL(ctx, object, http.MethodGet, ttl, opts)
+}
+
+// SignedUploadURL generates a signed URL that allows performing an HTTP PUT
+// request to upload the named object, without requiring further authentication.
+func (b *Bucket) SignedUploadURL(ctx context.Context, object string, ttl time.Duration, opts ...SignedURLOption) (*SignedURL, error) {
+	return b.signedURL(ctx, object, http.MethodPut, ttl, opts)
+}
+
+// SignedDeleteURL generates a signed URL that allows performing an HTTP DELETE
+// request to remove the named object, without requiring further authentication.
+func (b *Bucket) SignedDeleteURL(ctx context.Context, object string, ttl time.Duration, opts ...SignedURLOption) (*SignedURL, error) {
+	return b.signedURL(ctx, object, http.MethodDelete, ttl, opts)
+}
+
+func (b *Bucket) signedURL(ctx context.Context, object, method string, ttl time.Duration, opts []SignedURLOption) (*SignedURL, error) {
+	var options signedURLOptions
+	for _, opt := range opts {
+		opt.applySignedURL(&options)
+	}
+
+	res, err := b.impl.SignedURL(types.SignedURLData{
+		Ctx:             ctx,
+		Object:          types.CloudObject(object),
+		Version:         options.version,
+		Method:          method,
+		TTL:             ttl,
+		ContentType:     options.contentType,
+		ContentMD5:      options.contentMD5,
+		ResponseHeaders: options.responseHeaders,
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return &SignedURL{URL: res.URL, ExpiresAt: res.ExpiresAt}, nil
+}
+
+// Copy copies the object named src to dst within the bucket. Use
+// WithSourceBucket to copy an object from a different bucket.
+func (b *Bucket) Copy(ctx context.Context, src, dst string, opts ...CopyOption) (*ObjectAttrs, error) {
+	var options copyOptions
+	for _, opt := range opts {
+		opt.applyCopy(&options)
+	}
+
+	var srcBucket types.BucketImpl
+	if options.srcBucket != nil {
+		srcBucket = options.srcBucket.impl
+	}
+
+	attrs, err := b.impl.Copy(types.CopyData{
+		Ctx:        ctx,
+		Src:        types.CloudObject(src),
+		SrcVersion: options.srcVersion,
+		SrcBucket:  srcBucket,
+		Dst:        types.CloudObject(dst),
+		Pre:        mapPreconditions(options.pre),
+		Metadata:   options.metadata,
+	})
+	return mapAttrs(attrs), mapErr(err)
+}
+
+// Compose creates the object named dst by concatenating the given source
+// objects, in order. All source objects must reside in this bucket.
+func (b *Bucket) Compose(ctx context.Context, dst string, sources []CloudObject, opts ...ComposeOption) (*ObjectAttrs, error) {
+	var options composeOptions
+	for _, opt := range opts {
+		opt.applyCompose(&options)
+	}
+
+	attrs, err := b.impl.Compose(types.ComposeData{
+		Ctx:      ctx,
+		Sources:  sources,
+		Dst:      types.CloudObject(dst),
+		Pre:      mapPreconditions(options.pre),
+		Metadata: options.metadata,
+	})
+	return mapAttrs(attrs), mapErr(err)
+}
+
+// UpdateAttrs updates the attributes of an existing object without
+// re-uploading its content.
+func (b *Bucket) UpdateAttrs(ctx context.Context, object string, attrs UploadAttrs, opts ...UpdateAttrsOption) (*ObjectAttrs, error) {
+	var options updateAttrsOptions
+	for _, opt := range opts {
+		opt.applyUpdateAttrs(&options)
+	}
+
+	res, err := b.impl.UpdateAttrs(types.UpdateAttrsData{
+		Ctx:           ctx,
+		Object:        types.CloudObject(object),
+		Version:       options.version,
+		Pre:           mapPreconditions(options.pre),
+		EncryptionKey: options.encryptionKey,
+		Attrs: types.UploadAttrs{
+			ContentType:        attrs.ContentType,
+			Metadata:           attrs.Metadata,
+			CacheControl:       attrs.CacheControl,
+			ContentEncoding:    attrs.ContentEncoding,
+			ContentDisposition: attrs.ContentDisposition,
+			ContentLanguage:    attrs.ContentLanguage,
+			CRC32C:             attrs.CRC32C,
+			MD5:                attrs.MD5,
+			StorageClass:       attrs.StorageClass,
+			EncryptionKey:      attrs.EncryptionKey,
+		},
+	})
+	return mapAttrs(res), mapErr(err)
+}
+
+func mapPreconditions(pre Preconditions) types.Preconditions {
+	return types.Preconditions{
+		NotExists:           pre.NotExists,
+		GenerationMatch:     pre.GenerationMatch,
+		GenerationNotMatch:  pre.GenerationNotMatch,
+		MetagenerationMatch: pre.MetagenerationMatch,
+		IfMatchETag:         pre.IfMatchETag,
+		IfNoneMatchETag:     pre.IfNoneMatchETag,
+	}
+}
+
+func mapAttrs(attrs *types.ObjectAttrs) *ObjectAttrs {
+	if attrs == nil {
+		return nil
+	}
+	return &ObjectAttrs{
+		Object:             attrs.Object.String(),
+		Version:            attrs.Version,
+		ContentType:        attrs.ContentType,
+		Size:               attrs.Size,
+		ETag:               attrs.ETag,
+		Metadata:           attrs.Metadata,
+		CacheControl:       attrs.CacheControl,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentDisposition: attrs.ContentDisposition,
+		ContentLanguage:    attrs.ContentLanguage,
+		CRC32C:             attrs.CRC32C,
+		MD5:                attrs.MD5,
+		StorageClass:       attrs.StorageClass,
+	}
+}
+
+func mapListEntry(entry *types.ListEntry) *ListEntry {
+	if entry == nil {
+		return nil
+	}
+	return &ListEntry{
+		Object:             entry.Object.String(),
+		Version:            entry.Version,
+		Size:               entry.Size,
+		ETag:               entry.ETag,
+		IsLatest:           entry.IsLatest,
+		DeletedAt:          entry.DeletedAt,
+		Metadata:           entry.Metadata,
+		CacheControl:       entry.CacheControl,
+		ContentEncoding:    entry.ContentEncoding,
+		ContentDisposition: entry.ContentDisposition,
+		ContentLanguage:    entry.ContentLanguage,
+		CRC32C:             entry.CRC32C,
+		MD5:                entry.MD5,
+		StorageClass:       entry.StorageClass,
+	}
+}
+
+// BucketVersioning provides control over a bucket's object versioning.
+//
+// See Bucket.Versioning.
+type BucketVersioning struct {
+	b *Bucket
+}
+
+// Versioning returns the versioning controls for the bucket.
+func (b *Bucket) Versioning() *BucketVersioning {
+	return &BucketVersioning{b: b}
+}
+
+// Enable turns on versioning for the bucket, causing future overwrites and
+// deletes to retain the previous version of the object rather than discard it.
+func (v *BucketVersioning) Enable(ctx context.Context) error {
+	return mapErr(v.b.impl.EnableVersioning(ctx))
+}
+
+// Suspend turns off versioning for the bucket. Existing object versions are
+// retained, but future overwrites and deletes will no longer create new ones.
+func (v *BucketVersioning) Suspend(ctx context.Context) error {
+	return mapErr(v.b.impl.SuspendVersioning(ctx))
+}
+
+// VersioningStatus describes whether a bucket's versioning is enabled.
+type VersioningStatus struct {
+	// Enabled reports whether versioning is currently enabled for the bucket.
+	Enabled bool
+}
+
+// Status reports whether versioning is currently enabled for the bucket.
+func (v *BucketVersioning) Status(ctx context.Context) (*VersioningStatus, error) {
+	status, err := v.b.impl.VersioningStatus(ctx)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return &VersioningStatus{Enabled: status.Enabled}, nil
+}
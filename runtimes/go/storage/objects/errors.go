@@ -0,0 +1,19 @@
+package objects
+
+import "encore.dev/storage/objects/internal/types"
+
+var (
+	// ErrObjectNotExist is returned when the object does not exist.
+	ErrObjectNotExist = types.ErrObjectNotExist
+
+	// ErrPreconditionFailed is returned when a precondition for an operation was not met.
+	ErrPreconditionFailed = types.ErrPreconditionFailed
+
+	// ErrNotSupported is returned when an operation is not supported by the
+	// bucket's cloud provider.
+	ErrNotSupported = types.ErrNotSupported
+)
+
+func mapErr(err error) error {
+	return err
+}
@@ -7,7 +7,10 @@ import (
 	"iter"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
+	"cloud.google.com/go/pubsub"
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
@@ -23,16 +26,33 @@ type Manager struct {
 	ctx     context.Context
 	runtime *config.Runtime
 	clients map[*config.BucketProvider]*storage.Client
+
+	signerMu sync.Mutex
+	signers  map[*config.BucketProvider]*signer
+
+	handlersMu sync.Mutex
+	handlers   map[string]func(context.Context, *types.Event) error
+
+	pubsubMu      sync.Mutex
+	pubsubClients map[*config.BucketProvider]*pubsub.Client
 }
 
 func NewManager(ctx context.Context, runtime *config.Runtime) *Manager {
-	return &Manager{ctx: ctx, runtime: runtime, clients: make(map[*config.BucketProvider]*storage.Client)}
+	return &Manager{
+		ctx:           ctx,
+		runtime:       runtime,
+		clients:       make(map[*config.BucketProvider]*storage.Client),
+		signers:       make(map[*config.BucketProvider]*signer),
+		pubsubClients: make(map[*config.BucketProvider]*pubsub.Client),
+	}
 }
 
 type bucket struct {
-	client *storage.Client
-	cfg    *config.Bucket
-	handle *storage.BucketHandle
+	mgr      *Manager
+	provider *config.BucketProvider
+	client   *storage.Client
+	cfg      *config.Bucket
+	handle   *storage.BucketHandle
 }
 
 func (mgr *Manager) ProviderName() string { return "gcs" }
@@ -44,7 +64,7 @@ func (mgr *Manager) Matches(cfg *config.BucketProvider) bool {
 func (mgr *Manager) NewBucket(provider *config.BucketProvider, runtimeCfg *config.Bucket) types.BucketImpl {
 	client := mgr.clientForProvider(provider)
 	handle := client.Bucket(runtimeCfg.CloudName)
-	return &bucket{client, runtimeCfg, handle}
+	return &bucket{mgr, provider, client, runtimeCfg, handle}
 }
 
 func (b *bucket) Download(data types.DownloadData) (types.Downloader, error) {
@@ -54,22 +74,63 @@ func (b *bucket) Download(data types.DownloadData) (types.Downloader, error) {
 			obj = obj.Generation(gen)
 		}
 	}
+	if cond := conditionsFor(data.Pre); cond != (storage.Conditions{}) {
+		obj = obj.If(cond)
+	}
+	if len(data.EncryptionKey) > 0 {
+		obj = obj.Key(data.EncryptionKey)
+	}
 	r, err := obj.NewReader(data.Ctx)
 	return r, mapErr(err)
 }
 
+// conditionsFor translates the provider-agnostic preconditions into GCS's
+// generation-based conditions. GCS doesn't support ETag-based preconditions,
+// so Preconditions.IfMatchETag and IfNoneMatchETag are ignored.
+func conditionsFor(pre types.Preconditions) storage.Conditions {
+	cond := storage.Conditions{DoesNotExist: pre.NotExists}
+	if pre.GenerationMatch != "" {
+		if gen, err := strconv.ParseInt(pre.GenerationMatch, 10, 64); err == nil {
+			cond.GenerationMatch = gen
+		}
+	}
+	if pre.GenerationNotMatch != "" {
+		if gen, err := strconv.ParseInt(pre.GenerationNotMatch, 10, 64); err == nil {
+			cond.GenerationNotMatch = gen
+		}
+	}
+	if pre.MetagenerationMatch != 0 {
+		cond.MetagenerationMatch = pre.MetagenerationMatch
+	}
+	return cond
+}
+
 func (b *bucket) Upload(data types.UploadData) (types.Uploader, error) {
 	ctx, cancel := context.WithCancelCause(data.Ctx)
 	obj := b.handle.Object(data.Object.String())
 
-	if data.Pre.NotExists {
-		obj = obj.If(storage.Conditions{
-			DoesNotExist: true,
-		})
+	if cond := conditionsFor(data.Pre); cond != (storage.Conditions{}) {
+		obj = obj.If(cond)
+	}
+
+	if len(data.Attrs.EncryptionKey) > 0 {
+		obj = obj.Key(data.Attrs.EncryptionKey)
 	}
 
 	w := obj.NewWriter(ctx)
 	w.ContentType = data.Attrs.ContentType
+	w.Metadata = data.Attrs.Metadata
+	w.CacheControl = data.Attrs.CacheControl
+	w.ContentEncoding = data.Attrs.ContentEncoding
+	w.ContentDisposition = data.Attrs.ContentDisposition
+	w.ContentLanguage = data.Attrs.ContentLanguage
+	w.StorageClass = data.Attrs.StorageClass
+	w.MD5 = data.Attrs.MD5
+	w.SendCRC32C = data.SendCRC32C
+	if data.Attrs.CRC32C != nil {
+		w.CRC32C = *data.Attrs.CRC32C
+		w.SendCRC32C = true
+	}
 
 	u := &uploader{
 		cancel: cancel,
@@ -105,27 +166,58 @@ func mapAttrs(attrs *storage.ObjectAttrs) *types.ObjectAttrs {
 	if attrs == nil {
 		return nil
 	}
+	var crc32c *uint32
+	if attrs.CRC32C != 0 {
+		v := attrs.CRC32C
+		crc32c = &v
+	}
 	return &types.ObjectAttrs{
-		Object:      types.CloudObject(attrs.Name),
-		Version:     strconv.FormatInt(attrs.Generation, 10),
-		ContentType: attrs.ContentType,
-		Size:        attrs.Size,
-		ETag:        attrs.Etag,
+		Object:             types.CloudObject(attrs.Name),
+		Version:            strconv.FormatInt(attrs.Generation, 10),
+		ContentType:        attrs.ContentType,
+		Size:               attrs.Size,
+		ETag:               attrs.Etag,
+		Metadata:           attrs.Metadata,
+		CacheControl:       attrs.CacheControl,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentDisposition: attrs.ContentDisposition,
+		ContentLanguage:    attrs.ContentLanguage,
+		CRC32C:             crc32c,
+		MD5:                attrs.MD5,
+		StorageClass:       attrs.StorageClass,
 	}
 }
 
 func mapListEntry(attrs *storage.ObjectAttrs) *types.ListEntry {
+	var deletedAt *time.Time
+	if !attrs.Deleted.IsZero() {
+		deletedAt = &attrs.Deleted
+	}
+	var crc32c *uint32
+	if attrs.CRC32C != 0 {
+		v := attrs.CRC32C
+		crc32c = &v
+	}
 	return &types.ListEntry{
-		Object: types.CloudObject(attrs.Name),
-		Size:   attrs.Size,
-		ETag:   attrs.Etag,
+		Object:             types.CloudObject(attrs.Name),
+		Version:            strconv.FormatInt(attrs.Generation, 10),
+		Size:               attrs.Size,
+		ETag:               attrs.Etag,
+		IsLatest:           attrs.Deleted.IsZero(),
+		DeletedAt:          deletedAt,
+		Metadata:           attrs.Metadata,
+		CacheControl:       attrs.CacheControl,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentDisposition: attrs.ContentDisposition,
+		ContentLanguage:    attrs.ContentLanguage,
+		CRC32C:             crc32c,
+		MD5:                attrs.MD5,
+		StorageClass:       attrs.StorageClass,
 	}
 }
 
 func (b *bucket) List(data types.ListData) iter.Seq2[*types.ListEntry, error] {
-	iter := b.handle.Objects(data.Ctx, &storage.Query{
-		Prefix: data.Prefix,
-	})
+	iter := b.handle.Objects(data.Ctx, queryFor(data))
 	var n int64
 	return func(yield func(*types.ListEntry, error) bool) {
 		for {
@@ -134,6 +226,12 @@ func (b *bucket) List(data types.ListData) iter.Seq2[*types.ListEntry, error] {
 				return
 			}
 
+			// Common prefixes (only returned when Delimiter is set) aren't
+			// real objects; List only emits objects, so skip them.
+			if res != nil && res.Prefix != "" {
+				continue
+			}
+
 			// Are we over the limit?
 			if data.Limit != nil && n >= *data.Limit {
 				return
@@ -152,6 +250,51 @@ func (b *bucket) List(data types.ListData) iter.Seq2[*types.ListEntry, error] {
 	}
 }
 
+func queryFor(data types.ListData) *storage.Query {
+	return &storage.Query{
+		Prefix:      data.Prefix,
+		Versions:    data.AllVersions,
+		Delimiter:   data.Delimiter,
+		StartOffset: data.StartOffset,
+		EndOffset:   data.EndOffset,
+	}
+}
+
+func (b *bucket) ListPaged(data types.ListData) (*types.ListPage, error) {
+	it := b.handle.Objects(data.Ctx, queryFor(data))
+
+	pageInfo := it.PageInfo()
+	pageInfo.MaxSize = data.PageSize
+	pageInfo.Token = data.PageToken
+
+	page := &types.ListPage{}
+	for {
+		res, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, mapErr(err)
+		}
+
+		if res.Prefix != "" {
+			page.Prefixes = append(page.Prefixes, res.Prefix)
+		} else {
+			page.Entries = append(page.Entries, mapListEntry(res))
+		}
+
+		// Stop once the iterator has delivered a full page; otherwise it
+		// transparently fetches the next page on the following Next() call
+		// and pageInfo.Token is left empty once the whole bucket is drained.
+		if pageInfo.Remaining() == 0 {
+			break
+		}
+	}
+
+	page.NextPageToken = pageInfo.Token
+	return page, nil
+}
+
 func (b *bucket) Remove(data types.RemoveData) error {
 	obj := b.handle.Object(data.Object.String())
 
@@ -160,11 +303,40 @@ func (b *bucket) Remove(data types.RemoveData) error {
 			obj = obj.Generation(gen)
 		}
 	}
+	if cond := conditionsFor(data.Pre); cond != (storage.Conditions{}) {
+		obj = obj.If(cond)
+	}
 
 	err := obj.Delete(data.Ctx)
 	return mapErr(err)
 }
 
+func (b *bucket) UpdateAttrs(data types.UpdateAttrsData) (*types.ObjectAttrs, error) {
+	obj := b.handle.Object(data.Object.String())
+
+	if data.Version != "" {
+		if gen, err := strconv.ParseInt(data.Version, 10, 64); err == nil {
+			obj = obj.Generation(gen)
+		}
+	}
+	if cond := conditionsFor(data.Pre); cond != (storage.Conditions{}) {
+		obj = obj.If(cond)
+	}
+	if len(data.EncryptionKey) > 0 {
+		obj = obj.Key(data.EncryptionKey)
+	}
+
+	attrs, err := obj.Update(data.Ctx, storage.ObjectAttrsToUpdate{
+		ContentType:        data.Attrs.ContentType,
+		ContentEncoding:    data.Attrs.ContentEncoding,
+		ContentDisposition: data.Attrs.ContentDisposition,
+		ContentLanguage:    data.Attrs.ContentLanguage,
+		CacheControl:       data.Attrs.CacheControl,
+		Metadata:           data.Attrs.Metadata,
+	})
+	return mapAttrs(attrs), mapErr(err)
+}
+
 func (b *bucket) Attrs(data types.AttrsData) (*types.ObjectAttrs, error) {
 	obj := b.handle.Object(data.Object.String())
 
@@ -173,11 +345,32 @@ func (b *bucket) Attrs(data types.AttrsData) (*types.ObjectAttrs, error) {
 			obj = obj.Generation(gen)
 		}
 	}
+	if len(data.EncryptionKey) > 0 {
+		obj = obj.Key(data.EncryptionKey)
+	}
 
 	resp, err := obj.Attrs(data.Ctx)
 	return mapAttrs(resp), mapErr(err)
 }
 
+func (b *bucket) EnableVersioning(ctx context.Context) error {
+	_, err := b.handle.Update(ctx, storage.BucketAttrsToUpdate{VersioningEnabled: true})
+	return mapErr(err)
+}
+
+func (b *bucket) SuspendVersioning(ctx context.Context) error {
+	_, err := b.handle.Update(ctx, storage.BucketAttrsToUpdate{VersioningEnabled: false})
+	return mapErr(err)
+}
+
+func (b *bucket) VersioningStatus(ctx context.Context) (*types.BucketVersioningStatus, error) {
+	attrs, err := b.handle.Attrs(ctx)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return &types.BucketVersioningStatus{Enabled: attrs.VersioningEnabled}, nil
+}
+
 func (mgr *Manager) clientForProvider(prov *config.BucketProvider) *storage.Client {
 	if client, ok := mgr.clients[prov]; ok {
 		return client
@@ -208,15 +401,14 @@ func mapErr(err error) error {
 		return types.ErrObjectNotExist
 	default:
 		// Handle precondition failures
-		{
-			var e *googleapi.Error
-			if ok := errors.As(err, &e); ok && e.Code == http.StatusPreconditionFailed {
-				return types.ErrPreconditionFailed
-			}
+		var e *googleapi.Error
+		if errors.As(err, &e) && (e.Code == http.StatusPreconditionFailed || e.Code == http.StatusNotModified) {
+			return types.ErrPreconditionFailed
 		}
 
-		{
-			if s, ok := status.FromError(err); ok && s.Code() == codes.AlreadyExists || s.Code() == codes.FailedPrecondition {
+		if s, ok := status.FromError(err); ok {
+			switch s.Code() {
+			case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
 				return types.ErrPreconditionFailed
 			}
 		}
@@ -0,0 +1,71 @@
+package gcs
+
+import (
+	"errors"
+	"strconv"
+
+	"cloud.google.com/go/storage"
+
+	"encore.dev/storage/objects/internal/types"
+)
+
+var errCrossProviderCopy = errors.New("objects: cannot copy between buckets from different cloud providers")
+
+func (b *bucket) Copy(data types.CopyData) (*types.ObjectAttrs, error) {
+	srcHandle := b.handle
+	if sb, ok := data.SrcBucket.(*bucket); ok && sb != nil {
+		srcHandle = sb.handle
+	} else if data.SrcBucket != nil {
+		return nil, errCrossProviderCopy
+	}
+
+	srcObj := srcHandle.Object(data.Src.String())
+	if data.SrcVersion != "" {
+		if gen, err := strconv.ParseInt(data.SrcVersion, 10, 64); err == nil {
+			srcObj = srcObj.Generation(gen)
+		}
+	}
+
+	dstObj := b.handle.Object(data.Dst.String())
+	if cond := conditionsFor(data.Pre); cond != (storage.Conditions{}) {
+		dstObj = dstObj.If(cond)
+	}
+
+	copier := dstObj.CopierFrom(srcObj)
+	applyMetadata(&copier.ObjectAttrs, data.Metadata)
+
+	attrs, err := copier.Run(data.Ctx)
+	return mapAttrs(attrs), mapErr(err)
+}
+
+func (b *bucket) Compose(data types.ComposeData) (*types.ObjectAttrs, error) {
+	srcObjs := make([]*storage.ObjectHandle, len(data.Sources))
+	for i, src := range data.Sources {
+		srcObjs[i] = b.handle.Object(src.String())
+	}
+
+	dstObj := b.handle.Object(data.Dst.String())
+	if cond := conditionsFor(data.Pre); cond != (storage.Conditions{}) {
+		dstObj = dstObj.If(cond)
+	}
+
+	composer := dstObj.ComposerFrom(srcObjs...)
+	applyMetadata(&composer.ObjectAttrs, data.Metadata)
+
+	attrs, err := composer.Run(data.Ctx)
+	return mapAttrs(attrs), mapErr(err)
+}
+
+// applyMetadata overrides dst's metadata fields with those from attrs, if given.
+func applyMetadata(dst *storage.ObjectAttrs, attrs *types.UploadAttrs) {
+	if attrs == nil {
+		return
+	}
+	dst.ContentType = attrs.ContentType
+	dst.Metadata = attrs.Metadata
+	dst.CacheControl = attrs.CacheControl
+	dst.ContentEncoding = attrs.ContentEncoding
+	dst.ContentDisposition = attrs.ContentDisposition
+	dst.ContentLanguage = attrs.ContentLanguage
+	dst.StorageClass = attrs.StorageClass
+}
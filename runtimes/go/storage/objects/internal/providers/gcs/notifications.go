@@ -0,0 +1,244 @@
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/storage"
+
+	"encore.dev/appruntime/exported/config"
+	"encore.dev/storage/objects/internal/types"
+)
+
+// Subscribe creates (or adopts, if one with the same topic, prefix and event
+// types already exists) a GCS object notification that publishes matching
+// events to a Pub/Sub topic Encore manages for this subscription, and starts
+// a pull subscriber that bridges messages arriving on that topic to
+// data.Handler for the lifetime of the Manager.
+func (b *bucket) Subscribe(data types.SubscribeData) error {
+	topicID := fmt.Sprintf("encore-bucket-%s-%s", b.cfg.CloudName, data.Name)
+
+	eventTypes := make([]string, 0, len(data.Events))
+	for _, e := range data.Events {
+		switch e {
+		case types.EventTypeObjectFinalized:
+			eventTypes = append(eventTypes, storage.ObjectFinalizeEvent)
+		case types.EventTypeObjectDeleted:
+			eventTypes = append(eventTypes, storage.ObjectDeleteEvent)
+		}
+	}
+
+	want := &storage.Notification{
+		TopicProjectID:   b.provider.GCS.ProjectID,
+		TopicID:          topicID,
+		EventTypes:       eventTypes,
+		ObjectNamePrefix: data.Prefix,
+		PayloadFormat:    storage.JSONPayload,
+	}
+
+	client, err := b.mgr.pubsubClientForProvider(b.provider)
+	if err != nil {
+		return fmt.Errorf("objects: pubsub client for %q: %w", data.Name, err)
+	}
+	topic, err := b.mgr.ensureTopic(client, topicID)
+	if err != nil {
+		return fmt.Errorf("objects: ensure pubsub topic for %q: %w", data.Name, err)
+	}
+
+	existing, err := b.handle.Notifications(b.mgr.ctx)
+	if err != nil {
+		return mapErr(err)
+	}
+	adopted := false
+	for _, n := range existing {
+		if notificationMatches(n, want) {
+			adopted = true
+			break
+		}
+	}
+	if !adopted {
+		if _, err := b.handle.AddNotification(b.mgr.ctx, want); err != nil {
+			return mapErr(err)
+		}
+	}
+
+	b.mgr.registerHandler(topicID, data.Handler)
+
+	sub, err := b.mgr.subscriptionFor(client, topic, topicID)
+	if err != nil {
+		return fmt.Errorf("objects: bridge pubsub subscription for %q: %w", data.Name, err)
+	}
+
+	go b.mgr.pullAndDispatch(sub, topicID)
+	return nil
+}
+
+// notificationMatches reports whether an existing notification already
+// covers the same topic, prefix and event types as want, so Subscribe can
+// adopt it instead of registering a duplicate on every app startup.
+func notificationMatches(existing, want *storage.Notification) bool {
+	if existing.TopicProjectID != want.TopicProjectID ||
+		existing.TopicID != want.TopicID ||
+		existing.ObjectNamePrefix != want.ObjectNamePrefix {
+		return false
+	}
+	if len(existing.EventTypes) != len(want.EventTypes) {
+		return false
+	}
+	have := make(map[string]bool, len(existing.EventTypes))
+	for _, e := range existing.EventTypes {
+		have[e] = true
+	}
+	for _, e := range want.EventTypes {
+		if !have[e] {
+			return false
+		}
+	}
+	return true
+}
+
+func (mgr *Manager) registerHandler(topicID string, handler func(ctx context.Context, event *types.Event) error) {
+	mgr.handlersMu.Lock()
+	defer mgr.handlersMu.Unlock()
+	if mgr.handlers == nil {
+		mgr.handlers = make(map[string]func(context.Context, *types.Event) error)
+	}
+	mgr.handlers[topicID] = handler
+}
+
+func (mgr *Manager) handlerFor(topicID string) (func(context.Context, *types.Event) error, bool) {
+	mgr.handlersMu.Lock()
+	defer mgr.handlersMu.Unlock()
+	handler, ok := mgr.handlers[topicID]
+	return handler, ok
+}
+
+// ensureTopic returns the Pub/Sub topic that notifications for topicID
+// publish to, creating it if it doesn't already exist. Both AddNotification
+// and the pull subscription bridging it to the handler depend on the topic
+// existing first, since GCS doesn't provision it automatically.
+func (mgr *Manager) ensureTopic(client *pubsub.Client, topicID string) (*pubsub.Topic, error) {
+	topic := client.Topic(topicID)
+	exists, err := topic.Exists(mgr.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check pubsub topic: %w", err)
+	}
+	if !exists {
+		topic, err = client.CreateTopic(mgr.ctx, topicID)
+		if err != nil {
+			return nil, fmt.Errorf("create pubsub topic: %w", err)
+		}
+	}
+	return topic, nil
+}
+
+// subscriptionFor returns the pull subscription that bridges topic to this
+// process, creating it if it doesn't already exist.
+func (mgr *Manager) subscriptionFor(client *pubsub.Client, topic *pubsub.Topic, topicID string) (*pubsub.Subscription, error) {
+	subID := topicID + "-sub"
+	sub := client.Subscription(subID)
+	exists, err := sub.Exists(mgr.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check pubsub subscription: %w", err)
+	}
+	if !exists {
+		sub, err = client.CreateSubscription(mgr.ctx, subID, pubsub.SubscriptionConfig{
+			Topic: topic,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create pubsub subscription: %w", err)
+		}
+	}
+	return sub, nil
+}
+
+func (mgr *Manager) pubsubClientForProvider(provider *config.BucketProvider) (*pubsub.Client, error) {
+	mgr.pubsubMu.Lock()
+	defer mgr.pubsubMu.Unlock()
+	if client, ok := mgr.pubsubClients[provider]; ok {
+		return client, nil
+	}
+
+	client, err := pubsub.NewClient(mgr.ctx, provider.GCS.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("create pubsub client: %w", err)
+	}
+
+	mgr.pubsubClients[provider] = client
+	return client, nil
+}
+
+// pullAndDispatch pulls messages published to the notification topic and
+// invokes the handler registered for it, until the Manager's context is
+// cancelled.
+func (mgr *Manager) pullAndDispatch(sub *pubsub.Subscription, topicID string) {
+	err := sub.Receive(mgr.ctx, func(ctx context.Context, msg *pubsub.Message) {
+		handler, ok := mgr.handlerFor(topicID)
+		if !ok {
+			msg.Nack()
+			return
+		}
+
+		event, err := eventFromNotification(msg)
+		if err != nil {
+			log.Printf("objects: dropping malformed bucket notification on topic %s: %s", topicID, err)
+			msg.Ack()
+			return
+		}
+
+		if err := handler(ctx, event); err != nil {
+			msg.Nack()
+			return
+		}
+		msg.Ack()
+	})
+	if err != nil && mgr.ctx.Err() == nil {
+		log.Printf("objects: pubsub subscriber for topic %s stopped: %s", topicID, err)
+	}
+}
+
+// gcsNotificationPayload is the JSON object resource representation GCS
+// publishes as the message data when PayloadFormat is storage.JSONPayload.
+// See https://cloud.google.com/storage/docs/pubsub-notifications#payload.
+type gcsNotificationPayload struct {
+	Name       string    `json:"name"`
+	Generation string    `json:"generation"`
+	Size       string    `json:"size"`
+	Updated    time.Time `json:"updated"`
+}
+
+func eventFromNotification(msg *pubsub.Message) (*types.Event, error) {
+	var payload gcsNotificationPayload
+	if err := json.Unmarshal(msg.Data, &payload); err != nil {
+		return nil, fmt.Errorf("decode notification payload: %w", err)
+	}
+
+	var eventType types.EventType
+	switch msg.Attributes["eventType"] {
+	case storage.ObjectFinalizeEvent:
+		eventType = types.EventTypeObjectFinalized
+	case storage.ObjectDeleteEvent:
+		eventType = types.EventTypeObjectDeleted
+	default:
+		return nil, fmt.Errorf("unsupported event type %q", msg.Attributes["eventType"])
+	}
+
+	var size int64
+	if payload.Size != "" {
+		if _, err := fmt.Sscanf(payload.Size, "%d", &size); err != nil {
+			return nil, fmt.Errorf("parse size: %w", err)
+		}
+	}
+
+	return &types.Event{
+		Object:    types.CloudObject(payload.Name),
+		Version:   payload.Generation,
+		Size:      size,
+		EventType: eventType,
+		EventTime: payload.Updated,
+	}, nil
+}
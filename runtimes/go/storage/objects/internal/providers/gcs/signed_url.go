@@ -0,0 +1,151 @@
+package gcs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	credentials "cloud.google.com/go/iam/credentials/apiv1"
+	"cloud.google.com/go/iam/credentials/apiv1/credentialspb"
+	"cloud.google.com/go/storage"
+	"golang.org/x/oauth2/google"
+
+	"encore.dev/storage/objects/internal/types"
+)
+
+// signer knows how to sign bytes on behalf of the bucket's service account,
+// without the caller needing to hold the account's private key directly.
+type signer struct {
+	// accessID is the service account email the signed URL is issued for.
+	accessID string
+
+	// privateKey is the PEM-encoded private key to sign with, if one is
+	// available directly (e.g. from a downloaded service account key file).
+	privateKey []byte
+
+	// signBytes signs the given bytes using the IAM credentials SignBlob API,
+	// used as a fallback when no private key is available, such as when
+	// running on GKE or Cloud Run with an attached service account.
+	signBytes func(ctx context.Context, b []byte) ([]byte, error)
+}
+
+// signerForBucket lazily resolves the signer to use for b's provider, caching
+// it for reuse across requests.
+func (b *bucket) signerForBucket(ctx context.Context) (*signer, error) {
+	mgr := b.mgr
+	mgr.signerMu.Lock()
+	defer mgr.signerMu.Unlock()
+
+	if s, ok := mgr.signers[b.provider]; ok {
+		return s, nil
+	}
+
+	creds, err := google.FindDefaultCredentials(ctx, storage.ScopeReadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("objects: resolve default credentials: %w", err)
+	}
+
+	var keyFile struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if len(creds.JSON) > 0 {
+		_ = json.Unmarshal(creds.JSON, &keyFile)
+	}
+
+	var s *signer
+	if keyFile.PrivateKey != "" {
+		// We have a private key directly (e.g. a downloaded service account key),
+		// so we can sign locally without any additional API calls.
+		s = &signer{
+			accessID:   keyFile.ClientEmail,
+			privateKey: []byte(keyFile.PrivateKey),
+		}
+	} else {
+		// No private key is available, such as when running on GKE or Cloud Run
+		// with an attached service account. Fall back to signing via the IAM
+		// credentials SignBlob API using the ambient credentials' identity.
+		accessID := keyFile.ClientEmail
+		if accessID == "" {
+			accessID, err = metadata.EmailWithContext(ctx, "default")
+			if err != nil {
+				return nil, fmt.Errorf("objects: resolve service account email: %w", err)
+			}
+		}
+
+		iamClient, err := credentials.NewIamCredentialsClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("objects: create IAM credentials client: %w", err)
+		}
+
+		s = &signer{
+			accessID: accessID,
+			signBytes: func(ctx context.Context, b []byte) ([]byte, error) {
+				resp, err := iamClient.SignBlob(ctx, &credentialspb.SignBlobRequest{
+					Name:    "projects/-/serviceAccounts/" + s.accessID,
+					Payload: b,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("objects: sign blob: %w", err)
+				}
+				return resp.SignedBlob, nil
+			},
+		}
+	}
+
+	mgr.signers[b.provider] = s
+	return s, nil
+}
+
+func (b *bucket) SignedURL(data types.SignedURLData) (*types.SignedURL, error) {
+	s, err := b.signerForBucket(data.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().Add(data.TTL)
+	opts := &storage.SignedURLOptions{
+		GoogleAccessID: s.accessID,
+		Method:         data.Method,
+		Expires:        expires,
+		Scheme:         storage.SigningSchemeV4,
+	}
+	// Content-Type and Content-MD5 requirements only apply to upload URLs.
+	if data.Method == "PUT" {
+		opts.ContentType = data.ContentType
+		if len(data.ContentMD5) > 0 {
+			opts.MD5 = base64.StdEncoding.EncodeToString(data.ContentMD5)
+		}
+	}
+	if s.privateKey != nil {
+		opts.PrivateKey = s.privateKey
+	} else {
+		opts.SignBytes = func(b []byte) ([]byte, error) {
+			return s.signBytes(data.Ctx, b)
+		}
+	}
+	if len(data.ResponseHeaders) > 0 {
+		qp := url.Values{}
+		for k, vs := range data.ResponseHeaders {
+			qp[k] = vs
+		}
+		opts.QueryParameters = qp
+	}
+	if data.Version != "" {
+		if opts.QueryParameters == nil {
+			opts.QueryParameters = url.Values{}
+		}
+		opts.QueryParameters.Set("generation", data.Version)
+	}
+
+	signedURL, err := b.handle.SignedURL(data.Object.String(), opts)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return &types.SignedURL{URL: signedURL, ExpiresAt: expires}, nil
+}
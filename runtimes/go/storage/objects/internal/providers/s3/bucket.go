@@ -0,0 +1,496 @@
+// Package s3 implements the object storage provider for buckets backed by
+// Amazon S3 (or an S3-compatible store).
+package s3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"encore.dev/appruntime/exported/config"
+	objtypes "encore.dev/storage/objects/internal/types"
+)
+
+type Manager struct {
+	ctx     context.Context
+	runtime *config.Runtime
+	clients map[*config.BucketProvider]*s3.Client
+}
+
+func NewManager(ctx context.Context, runtime *config.Runtime) *Manager {
+	return &Manager{ctx: ctx, runtime: runtime, clients: make(map[*config.BucketProvider]*s3.Client)}
+}
+
+func (mgr *Manager) ProviderName() string { return "s3" }
+
+func (mgr *Manager) Matches(cfg *config.BucketProvider) bool {
+	return cfg.S3 != nil
+}
+
+func (mgr *Manager) NewBucket(provider *config.BucketProvider, runtimeCfg *config.Bucket) objtypes.BucketImpl {
+	client := mgr.clientForProvider(provider)
+	return &bucket{client: client, cfg: runtimeCfg, presign: s3.NewPresignClient(client)}
+}
+
+type bucket struct {
+	client  *s3.Client
+	cfg     *config.Bucket
+	presign *s3.PresignClient
+}
+
+func (b *bucket) Download(data objtypes.DownloadData) (objtypes.Downloader, error) {
+	in := &s3.GetObjectInput{
+		Bucket: aws.String(b.cfg.CloudName),
+		Key:    aws.String(data.Object.String()),
+	}
+	if data.Version != "" {
+		in.VersionId = aws.String(data.Version)
+	}
+	if data.Pre.IfMatchETag != "" {
+		in.IfMatch = aws.String(data.Pre.IfMatchETag)
+	}
+	if data.Pre.IfNoneMatchETag != "" {
+		in.IfNoneMatch = aws.String(data.Pre.IfNoneMatchETag)
+	}
+	if len(data.EncryptionKey) > 0 {
+		in.SSECustomerAlgorithm = aws.String("AES256")
+		in.SSECustomerKey = aws.String(string(data.EncryptionKey))
+	}
+
+	resp, err := b.client.GetObject(data.Ctx, in)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return resp.Body, nil
+}
+
+// Upload honors data.Pre.NotExists and the ETag preconditions via S3's
+// conditional PutObject parameters (If-None-Match, If-Match). S3 has no
+// concept of object generations, so GenerationMatch, GenerationNotMatch, and
+// MetagenerationMatch are ignored.
+func (b *bucket) Upload(data objtypes.UploadData) (objtypes.Uploader, error) {
+	return newUploader(data, b.client, b.cfg.CloudName), nil
+}
+
+// List doesn't support ListData.EndOffset, for the same reason as ListPaged.
+// StartOffset maps to S3's StartAfter, which is exclusive: an object named
+// exactly StartOffset is not returned. When AllVersions is set, Delimiter and
+// StartOffset are ignored as well.
+func (b *bucket) List(data objtypes.ListData) iter.Seq2[*objtypes.ListEntry, error] {
+	if data.AllVersions {
+		return b.listVersions(data)
+	}
+
+	in := &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.cfg.CloudName),
+		Prefix: aws.String(data.Prefix),
+	}
+	if data.Delimiter != "" {
+		in.Delimiter = aws.String(data.Delimiter)
+	}
+	if data.StartOffset != "" {
+		in.StartAfter = aws.String(data.StartOffset)
+	}
+	paginator := s3.NewListObjectsV2Paginator(b.client, in)
+
+	var n int64
+	return func(yield func(*objtypes.ListEntry, error) bool) {
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(data.Ctx)
+			if err != nil {
+				yield(nil, mapErr(err))
+				return
+			}
+
+			for _, obj := range page.Contents {
+				if data.Limit != nil && n >= *data.Limit {
+					return
+				}
+				n++
+
+				if !yield(mapListEntry(obj), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ListPaged doesn't support ListData.EndOffset: S3's ListObjectsV2 API has no
+// equivalent of an exclusive upper bound on the key range. StartOffset maps to
+// S3's StartAfter, which is exclusive: an object named exactly StartOffset is
+// not returned. When AllVersions is set, StartOffset is ignored as well.
+func (b *bucket) ListPaged(data objtypes.ListData) (*objtypes.ListPage, error) {
+	if data.AllVersions {
+		return b.listVersionsPaged(data)
+	}
+
+	in := &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.cfg.CloudName),
+		Prefix: aws.String(data.Prefix),
+	}
+	if data.Delimiter != "" {
+		in.Delimiter = aws.String(data.Delimiter)
+	}
+	if data.StartOffset != "" {
+		in.StartAfter = aws.String(data.StartOffset)
+	}
+	if data.PageToken != "" {
+		in.ContinuationToken = aws.String(data.PageToken)
+	}
+	if data.PageSize > 0 {
+		in.MaxKeys = aws.Int32(int32(data.PageSize))
+	}
+
+	resp, err := b.client.ListObjectsV2(data.Ctx, in)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	page := &objtypes.ListPage{NextPageToken: aws.ToString(resp.NextContinuationToken)}
+	for _, obj := range resp.Contents {
+		page.Entries = append(page.Entries, mapListEntry(obj))
+	}
+	for _, p := range resp.CommonPrefixes {
+		page.Prefixes = append(page.Prefixes, aws.ToString(p.Prefix))
+	}
+	return page, nil
+}
+
+func (b *bucket) listVersions(data objtypes.ListData) iter.Seq2[*objtypes.ListEntry, error] {
+	in := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(b.cfg.CloudName),
+		Prefix: aws.String(data.Prefix),
+	}
+	paginator := s3.NewListObjectVersionsPaginator(b.client, in)
+
+	var n int64
+	return func(yield func(*objtypes.ListEntry, error) bool) {
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(data.Ctx)
+			if err != nil {
+				yield(nil, mapErr(err))
+				return
+			}
+
+			for _, v := range page.Versions {
+				if data.Limit != nil && n >= *data.Limit {
+					return
+				}
+				n++
+
+				if !yield(mapObjectVersion(v), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// listVersionsPaged is ListPaged's counterpart to listVersions. S3 needs both
+// NextKeyMarker and NextVersionIdMarker to resume correctly when a single key
+// has more versions than fit on a page, so the two are packed into the
+// opaque NextPageToken and split back out on the next call.
+func (b *bucket) listVersionsPaged(data objtypes.ListData) (*objtypes.ListPage, error) {
+	in := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(b.cfg.CloudName),
+		Prefix: aws.String(data.Prefix),
+	}
+	if data.Delimiter != "" {
+		in.Delimiter = aws.String(data.Delimiter)
+	}
+	if data.PageToken != "" {
+		keyMarker, versionIDMarker := splitVersionsPageToken(data.PageToken)
+		in.KeyMarker = aws.String(keyMarker)
+		if versionIDMarker != "" {
+			in.VersionIdMarker = aws.String(versionIDMarker)
+		}
+	}
+	if data.PageSize > 0 {
+		in.MaxKeys = aws.Int32(int32(data.PageSize))
+	}
+
+	resp, err := b.client.ListObjectVersions(data.Ctx, in)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	page := &objtypes.ListPage{
+		NextPageToken: joinVersionsPageToken(aws.ToString(resp.NextKeyMarker), aws.ToString(resp.NextVersionIdMarker)),
+	}
+	for _, v := range resp.Versions {
+		page.Entries = append(page.Entries, mapObjectVersion(v))
+	}
+	for _, p := range resp.CommonPrefixes {
+		page.Prefixes = append(page.Prefixes, aws.ToString(p.Prefix))
+	}
+	return page, nil
+}
+
+// versionsPageToken is the opaque payload packed into a versioned ListPaged
+// NextPageToken: S3 needs both markers to resume a page boundary that falls
+// in the middle of a key's versions.
+type versionsPageToken struct {
+	KeyMarker       string `json:"k"`
+	VersionIDMarker string `json:"v,omitempty"`
+}
+
+func joinVersionsPageToken(keyMarker, versionIDMarker string) string {
+	if keyMarker == "" {
+		return ""
+	}
+	b, err := json.Marshal(versionsPageToken{KeyMarker: keyMarker, VersionIDMarker: versionIDMarker})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func splitVersionsPageToken(token string) (keyMarker, versionIDMarker string) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return token, ""
+	}
+	var t versionsPageToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return token, ""
+	}
+	return t.KeyMarker, t.VersionIDMarker
+}
+
+// Remove doesn't honor data.Pre: S3's DeleteObject API has no conditional
+// request parameters.
+func (b *bucket) Remove(data objtypes.RemoveData) error {
+	in := &s3.DeleteObjectInput{
+		Bucket: aws.String(b.cfg.CloudName),
+		Key:    aws.String(data.Object.String()),
+	}
+	if data.Version != "" {
+		in.VersionId = aws.String(data.Version)
+	}
+
+	_, err := b.client.DeleteObject(data.Ctx, in)
+	return mapErr(err)
+}
+
+func (b *bucket) Attrs(data objtypes.AttrsData) (*objtypes.ObjectAttrs, error) {
+	in := &s3.HeadObjectInput{
+		Bucket: aws.String(b.cfg.CloudName),
+		Key:    aws.String(data.Object.String()),
+	}
+	if data.Version != "" {
+		in.VersionId = aws.String(data.Version)
+	}
+	if len(data.EncryptionKey) > 0 {
+		in.SSECustomerAlgorithm = aws.String("AES256")
+		in.SSECustomerKey = aws.String(string(data.EncryptionKey))
+	}
+
+	resp, err := b.client.HeadObject(data.Ctx, in)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return &objtypes.ObjectAttrs{
+		Object:             data.Object,
+		Version:            aws.ToString(resp.VersionId),
+		ContentType:        aws.ToString(resp.ContentType),
+		Size:               aws.ToInt64(resp.ContentLength),
+		ETag:               aws.ToString(resp.ETag),
+		Metadata:           resp.Metadata,
+		CacheControl:       aws.ToString(resp.CacheControl),
+		ContentEncoding:    aws.ToString(resp.ContentEncoding),
+		ContentDisposition: aws.ToString(resp.ContentDisposition),
+		ContentLanguage:    aws.ToString(resp.ContentLanguage),
+		StorageClass:       string(resp.StorageClass),
+	}, nil
+}
+
+func (b *bucket) SignedURL(data objtypes.SignedURLData) (*objtypes.SignedURL, error) {
+	expires := time.Now().Add(data.TTL)
+
+	var url string
+	var err error
+	switch data.Method {
+	case "GET":
+		in := &s3.GetObjectInput{
+			Bucket: aws.String(b.cfg.CloudName),
+			Key:    aws.String(data.Object.String()),
+		}
+		if data.Version != "" {
+			in.VersionId = aws.String(data.Version)
+		}
+		if err := applyResponseHeaders(in, data.ResponseHeaders); err != nil {
+			return nil, err
+		}
+
+		var out *v4.PresignedHTTPRequest
+		out, err = b.presign.PresignGetObject(data.Ctx, in, s3.WithPresignExpires(data.TTL))
+		if out != nil {
+			url = out.URL
+		}
+	case "PUT":
+		in := &s3.PutObjectInput{
+			Bucket:      aws.String(b.cfg.CloudName),
+			Key:         aws.String(data.Object.String()),
+			ContentType: aws.String(data.ContentType),
+		}
+		if len(data.ContentMD5) > 0 {
+			in.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(data.ContentMD5))
+		}
+
+		var out *v4.PresignedHTTPRequest
+		out, err = b.presign.PresignPutObject(data.Ctx, in, s3.WithPresignExpires(data.TTL))
+		if out != nil {
+			url = out.URL
+		}
+	case "DELETE":
+		in := &s3.DeleteObjectInput{
+			Bucket: aws.String(b.cfg.CloudName),
+			Key:    aws.String(data.Object.String()),
+		}
+		if data.Version != "" {
+			in.VersionId = aws.String(data.Version)
+		}
+
+		var out *v4.PresignedHTTPRequest
+		out, err = b.presign.PresignDeleteObject(data.Ctx, in, s3.WithPresignExpires(data.TTL))
+		if out != nil {
+			url = out.URL
+		}
+	default:
+		return nil, fmt.Errorf("objects: unsupported signed URL method %q", data.Method)
+	}
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return &objtypes.SignedURL{URL: url, ExpiresAt: expires}, nil
+}
+
+// signedURLResponseHeaders maps the header names WithSignedURLResponseHeader
+// accepts to the corresponding ResponseXxx field on s3.GetObjectInput. S3's
+// presigned GET, unlike GCS's, only lets the response override this fixed
+// set of headers.
+var signedURLResponseHeaders = map[string]func(in *s3.GetObjectInput, value string){
+	"Cache-Control":       func(in *s3.GetObjectInput, v string) { in.ResponseCacheControl = aws.String(v) },
+	"Content-Disposition": func(in *s3.GetObjectInput, v string) { in.ResponseContentDisposition = aws.String(v) },
+	"Content-Encoding":    func(in *s3.GetObjectInput, v string) { in.ResponseContentEncoding = aws.String(v) },
+	"Content-Language":    func(in *s3.GetObjectInput, v string) { in.ResponseContentLanguage = aws.String(v) },
+	"Content-Type":        func(in *s3.GetObjectInput, v string) { in.ResponseContentType = aws.String(v) },
+}
+
+func applyResponseHeaders(in *s3.GetObjectInput, headers map[string][]string) error {
+	for header, values := range headers {
+		apply, ok := signedURLResponseHeaders[http.CanonicalHeaderKey(header)]
+		if !ok {
+			return fmt.Errorf("objects: S3 signed URLs don't support overriding the %q response header", header)
+		}
+		if len(values) > 0 {
+			apply(in, values[0])
+		}
+	}
+	return nil
+}
+
+func mapListEntry(obj types.Object) *objtypes.ListEntry {
+	return &objtypes.ListEntry{
+		Object:       objtypes.CloudObject(aws.ToString(obj.Key)),
+		Size:         aws.ToInt64(obj.Size),
+		ETag:         aws.ToString(obj.ETag),
+		StorageClass: string(obj.StorageClass),
+	}
+}
+
+func mapObjectVersion(v types.ObjectVersion) *objtypes.ListEntry {
+	return &objtypes.ListEntry{
+		Object:       objtypes.CloudObject(aws.ToString(v.Key)),
+		Version:      aws.ToString(v.VersionId),
+		Size:         aws.ToInt64(v.Size),
+		ETag:         aws.ToString(v.ETag),
+		IsLatest:     aws.ToBool(v.IsLatest),
+		StorageClass: string(v.StorageClass),
+	}
+}
+
+func (b *bucket) EnableVersioning(ctx context.Context) error {
+	_, err := b.client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket: aws.String(b.cfg.CloudName),
+		VersioningConfiguration: &types.VersioningConfiguration{
+			Status: types.BucketVersioningStatusEnabled,
+		},
+	})
+	return mapErr(err)
+}
+
+func (b *bucket) SuspendVersioning(ctx context.Context) error {
+	_, err := b.client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket: aws.String(b.cfg.CloudName),
+		VersioningConfiguration: &types.VersioningConfiguration{
+			Status: types.BucketVersioningStatusSuspended,
+		},
+	})
+	return mapErr(err)
+}
+
+func (b *bucket) VersioningStatus(ctx context.Context) (*objtypes.BucketVersioningStatus, error) {
+	resp, err := b.client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(b.cfg.CloudName),
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return &objtypes.BucketVersioningStatus{Enabled: resp.Status == types.BucketVersioningStatusEnabled}, nil
+}
+
+func (mgr *Manager) clientForProvider(prov *config.BucketProvider) *s3.Client {
+	if client, ok := mgr.clients[prov]; ok {
+		return client
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(mgr.ctx)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load AWS config: %s", err))
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if prov.S3.Endpoint != "" {
+			o.BaseEndpoint = aws.String(prov.S3.Endpoint)
+		}
+		if prov.S3.Region != "" {
+			o.Region = prov.S3.Region
+		}
+	})
+
+	mgr.clients[prov] = client
+	return client
+}
+
+func mapErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var nf *types.NoSuchKey
+	if errors.As(err, &nf) {
+		return objtypes.ErrObjectNotExist
+	}
+
+	var pc *smithyhttp.ResponseError
+	if errors.As(err, &pc) && (pc.Response.StatusCode == 412 || pc.Response.StatusCode == 304) {
+		return objtypes.ErrPreconditionFailed
+	}
+
+	return err
+}
@@ -0,0 +1,66 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestMapListEntry(t *testing.T) {
+	entry := mapListEntry(types.Object{
+		Key:          aws.String("foo/bar.txt"),
+		Size:         aws.Int64(42),
+		ETag:         aws.String(`"abc"`),
+		StorageClass: types.ObjectStorageClassGlacier,
+	})
+
+	if entry.Object.String() != "foo/bar.txt" {
+		t.Errorf("Object = %q, want %q", entry.Object, "foo/bar.txt")
+	}
+	if entry.Size != 42 {
+		t.Errorf("Size = %d, want 42", entry.Size)
+	}
+	if entry.ETag != `"abc"` {
+		t.Errorf("ETag = %q, want %q", entry.ETag, `"abc"`)
+	}
+	if entry.StorageClass != string(types.ObjectStorageClassGlacier) {
+		t.Errorf("StorageClass = %q, want %q", entry.StorageClass, types.ObjectStorageClassGlacier)
+	}
+}
+
+func TestMapObjectVersion(t *testing.T) {
+	entry := mapObjectVersion(types.ObjectVersion{
+		Key:          aws.String("foo/bar.txt"),
+		VersionId:    aws.String("v1"),
+		Size:         aws.Int64(42),
+		ETag:         aws.String(`"abc"`),
+		IsLatest:     aws.Bool(true),
+		StorageClass: types.ObjectVersionStorageClassStandard,
+	})
+
+	if entry.Version != "v1" {
+		t.Errorf("Version = %q, want %q", entry.Version, "v1")
+	}
+	if !entry.IsLatest {
+		t.Error("IsLatest = false, want true")
+	}
+	if entry.StorageClass != string(types.ObjectVersionStorageClassStandard) {
+		t.Errorf("StorageClass = %q, want %q", entry.StorageClass, types.ObjectVersionStorageClassStandard)
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	cases := []struct {
+		bucket, key, want string
+	}{
+		{"my-bucket", "foo/bar.txt", "my-bucket/foo/bar.txt"},
+		{"my-bucket", "my file.txt", "my-bucket/my%20file.txt"},
+		{"my-bucket", "a/b c/d.txt", "my-bucket/a/b%20c/d.txt"},
+	}
+	for _, c := range cases {
+		if got := copySource(c.bucket, c.key); got != c.want {
+			t.Errorf("copySource(%q, %q) = %q, want %q", c.bucket, c.key, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package s3
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	objtypes "encore.dev/storage/objects/internal/types"
+)
+
+// copySource builds the value of the CopySource header/parameter CopyObject
+// and UploadPartCopy expect, which AWS requires to be URL-encoded. Each path
+// segment of the key is escaped independently (rather than escaping the
+// whole key, or using QueryEscape) so that '/' separators in the key survive
+// and spaces don't get encoded as the form-style '+' that S3 won't decode
+// back to a space.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return url.PathEscape(bucket) + "/" + strings.Join(segments, "/")
+}
+
+// Copy honors data.Pre.NotExists and the ETag preconditions on the
+// destination object, via S3's conditional CopyObject parameters (IfMatch,
+// IfNoneMatch). S3 has no concept of object generations, so GenerationMatch,
+// GenerationNotMatch, and MetagenerationMatch are ignored.
+func (b *bucket) Copy(data objtypes.CopyData) (*objtypes.ObjectAttrs, error) {
+	srcBucket := b.cfg.CloudName
+	if sb, ok := data.SrcBucket.(*bucket); ok && sb != nil {
+		srcBucket = sb.cfg.CloudName
+	} else if data.SrcBucket != nil {
+		return nil, fmt.Errorf("objects: cannot copy between buckets from different cloud providers")
+	}
+
+	src := copySource(srcBucket, data.Src.String())
+	if data.SrcVersion != "" {
+		src = fmt.Sprintf("%s?versionId=%s", src, data.SrcVersion)
+	}
+
+	in := &s3.CopyObjectInput{
+		Bucket:     aws.String(b.cfg.CloudName),
+		Key:        aws.String(data.Dst.String()),
+		CopySource: aws.String(src),
+	}
+	if data.Metadata != nil {
+		in.ContentType = aws.String(data.Metadata.ContentType)
+		in.Metadata = data.Metadata.Metadata
+		in.MetadataDirective = types.MetadataDirectiveReplace
+		if data.Metadata.CacheControl != "" {
+			in.CacheControl = aws.String(data.Metadata.CacheControl)
+		}
+		if data.Metadata.ContentEncoding != "" {
+			in.ContentEncoding = aws.String(data.Metadata.ContentEncoding)
+		}
+		if data.Metadata.ContentDisposition != "" {
+			in.ContentDisposition = aws.String(data.Metadata.ContentDisposition)
+		}
+		if data.Metadata.ContentLanguage != "" {
+			in.ContentLanguage = aws.String(data.Metadata.ContentLanguage)
+		}
+		if data.Metadata.StorageClass != "" {
+			in.StorageClass = types.StorageClass(data.Metadata.StorageClass)
+		}
+	}
+	switch {
+	case data.Pre.IfNoneMatchETag != "":
+		in.IfNoneMatch = aws.String(data.Pre.IfNoneMatchETag)
+	case data.Pre.NotExists:
+		in.IfNoneMatch = aws.String("*")
+	}
+	if data.Pre.IfMatchETag != "" {
+		in.IfMatch = aws.String(data.Pre.IfMatchETag)
+	}
+
+	if _, err := b.client.CopyObject(data.Ctx, in); err != nil {
+		return nil, mapErr(err)
+	}
+
+	return b.Attrs(objtypes.AttrsData{Ctx: data.Ctx, Object: data.Dst})
+}
+
+func (b *bucket) Compose(data objtypes.ComposeData) (*objtypes.ObjectAttrs, error) {
+	return nil, fmt.Errorf("objects: Compose is not supported by the S3 provider: %w", objtypes.ErrNotSupported)
+}
+
+// Subscribe is not yet implemented for the S3 provider. Bucket notifications
+// would be wired up via S3 event notifications to an SNS topic (or SQS
+// queue), analogous to the GCS provider's Pub/Sub-backed notifications.
+//
+// It wraps objtypes.ErrNotSupported rather than just returning a plain error
+// because, unlike most BucketImpl methods, Subscribe is only ever called
+// from a package-level NewSubscription declaration: NewSubscription can't
+// return an error to the caller, so it distinguishes this structural,
+// always-true incompatibility from an operational failure and logs instead
+// of crashing the process at startup.
+func (b *bucket) Subscribe(data objtypes.SubscribeData) error {
+	return fmt.Errorf("objects: bucket notifications are not yet supported by the S3 provider: %w", objtypes.ErrNotSupported)
+}
+
+// UpdateAttrs is implemented as a copy of the object onto itself with
+// MetadataDirectiveReplace, since S3 has no in-place metadata update API.
+func (b *bucket) UpdateAttrs(data objtypes.UpdateAttrsData) (*objtypes.ObjectAttrs, error) {
+	src := copySource(b.cfg.CloudName, data.Object.String())
+	if data.Version != "" {
+		src = fmt.Sprintf("%s?versionId=%s", src, data.Version)
+	}
+
+	in := &s3.CopyObjectInput{
+		Bucket:            aws.String(b.cfg.CloudName),
+		Key:               aws.String(data.Object.String()),
+		CopySource:        aws.String(src),
+		MetadataDirective: types.MetadataDirectiveReplace,
+		ContentType:       aws.String(data.Attrs.ContentType),
+		Metadata:          data.Attrs.Metadata,
+	}
+	if data.Attrs.CacheControl != "" {
+		in.CacheControl = aws.String(data.Attrs.CacheControl)
+	}
+	if data.Attrs.ContentEncoding != "" {
+		in.ContentEncoding = aws.String(data.Attrs.ContentEncoding)
+	}
+	if data.Attrs.ContentDisposition != "" {
+		in.ContentDisposition = aws.String(data.Attrs.ContentDisposition)
+	}
+	if data.Attrs.ContentLanguage != "" {
+		in.ContentLanguage = aws.String(data.Attrs.ContentLanguage)
+	}
+	if data.Pre.IfMatchETag != "" {
+		in.CopySourceIfMatch = aws.String(data.Pre.IfMatchETag)
+	}
+	if data.Pre.IfNoneMatchETag != "" {
+		in.CopySourceIfNoneMatch = aws.String(data.Pre.IfNoneMatchETag)
+	}
+	if len(data.EncryptionKey) > 0 {
+		// UpdateAttrs copies the object onto itself, so the same
+		// customer-supplied key decrypts the source and re-encrypts the
+		// destination.
+		in.CopySourceSSECustomerAlgorithm = aws.String("AES256")
+		in.CopySourceSSECustomerKey = aws.String(string(data.EncryptionKey))
+		in.SSECustomerAlgorithm = aws.String("AES256")
+		in.SSECustomerKey = aws.String(string(data.EncryptionKey))
+	}
+
+	if _, err := b.client.CopyObject(data.Ctx, in); err != nil {
+		return nil, mapErr(err)
+	}
+
+	return b.Attrs(objtypes.AttrsData{Ctx: data.Ctx, Object: data.Object, EncryptionKey: data.EncryptionKey})
+}
@@ -0,0 +1,154 @@
+package s3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	objtypes "encore.dev/storage/objects/internal/types"
+)
+
+// uploader streams the object's contents to a managed multipart upload
+// through an io.Pipe as Write is called, rather than buffering the whole
+// object in memory, mirroring the write-then-commit semantics the other
+// providers expose via their SDK's streaming writer.
+type uploader struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	bucket string
+	key    string
+
+	pw   *io.PipeWriter
+	done chan struct{}
+
+	result *objtypes.ObjectAttrs
+	err    error
+}
+
+func newUploader(data objtypes.UploadData, client *s3.Client, bucketName string) *uploader {
+	ctx, cancel := context.WithCancelCause(data.Ctx)
+
+	pr, pw := io.Pipe()
+	u := &uploader{
+		ctx:    ctx,
+		cancel: cancel,
+		bucket: bucketName,
+		key:    data.Object.String(),
+		pw:     pw,
+		done:   make(chan struct{}),
+	}
+
+	in := putObjectInput(bucketName, u.key, data.Attrs, data.Pre, data.SendCRC32C, pr)
+
+	go func() {
+		defer close(u.done)
+
+		if _, err := manager.NewUploader(client).Upload(ctx, in); err != nil {
+			u.err = mapErr(err)
+			return
+		}
+
+		headIn := &s3.HeadObjectInput{Bucket: aws.String(bucketName), Key: aws.String(u.key)}
+		if len(data.Attrs.EncryptionKey) > 0 {
+			headIn.SSECustomerAlgorithm = aws.String("AES256")
+			headIn.SSECustomerKey = aws.String(string(data.Attrs.EncryptionKey))
+		}
+		resp, err := client.HeadObject(ctx, headIn)
+		if err != nil {
+			u.err = mapErr(err)
+			return
+		}
+
+		u.result = &objtypes.ObjectAttrs{
+			Object:             objtypes.CloudObject(u.key),
+			ContentType:        aws.ToString(resp.ContentType),
+			Size:               aws.ToInt64(resp.ContentLength),
+			ETag:               aws.ToString(resp.ETag),
+			Metadata:           resp.Metadata,
+			CacheControl:       aws.ToString(resp.CacheControl),
+			ContentEncoding:    aws.ToString(resp.ContentEncoding),
+			ContentDisposition: aws.ToString(resp.ContentDisposition),
+			ContentLanguage:    aws.ToString(resp.ContentLanguage),
+			StorageClass:       string(resp.StorageClass),
+		}
+	}()
+
+	return u
+}
+
+func putObjectInput(bucket, key string, attrs objtypes.UploadAttrs, pre objtypes.Preconditions, sendCRC32C bool, body io.Reader) *s3.PutObjectInput {
+	in := &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(attrs.ContentType),
+		Metadata:    attrs.Metadata,
+	}
+	if attrs.CacheControl != "" {
+		in.CacheControl = aws.String(attrs.CacheControl)
+	}
+	if attrs.ContentEncoding != "" {
+		in.ContentEncoding = aws.String(attrs.ContentEncoding)
+	}
+	if attrs.ContentDisposition != "" {
+		in.ContentDisposition = aws.String(attrs.ContentDisposition)
+	}
+	if attrs.ContentLanguage != "" {
+		in.ContentLanguage = aws.String(attrs.ContentLanguage)
+	}
+	if attrs.StorageClass != "" {
+		in.StorageClass = types.StorageClass(attrs.StorageClass)
+	}
+	if len(attrs.MD5) > 0 {
+		in.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(attrs.MD5))
+	}
+	switch {
+	case attrs.CRC32C != nil:
+		var crc [4]byte
+		binary.BigEndian.PutUint32(crc[:], *attrs.CRC32C)
+		in.ChecksumAlgorithm = types.ChecksumAlgorithmCrc32c
+		in.ChecksumCRC32C = aws.String(base64.StdEncoding.EncodeToString(crc[:]))
+	case sendCRC32C:
+		in.ChecksumAlgorithm = types.ChecksumAlgorithmCrc32c
+	}
+	if len(attrs.EncryptionKey) > 0 {
+		in.SSECustomerAlgorithm = aws.String("AES256")
+		in.SSECustomerKey = aws.String(string(attrs.EncryptionKey))
+	}
+	switch {
+	case pre.IfNoneMatchETag != "":
+		in.IfNoneMatch = aws.String(pre.IfNoneMatchETag)
+	case pre.NotExists:
+		in.IfNoneMatch = aws.String("*")
+	}
+	if pre.IfMatchETag != "" {
+		in.IfMatch = aws.String(pre.IfMatchETag)
+	}
+	return in
+}
+
+func (u *uploader) Write(p []byte) (int, error) {
+	return u.pw.Write(p)
+}
+
+func (u *uploader) Complete() (*objtypes.ObjectAttrs, error) {
+	if err := u.pw.Close(); err != nil {
+		return nil, mapErr(err)
+	}
+	<-u.done
+	if u.err != nil {
+		return nil, u.err
+	}
+	return u.result, nil
+}
+
+func (u *uploader) Abort(err error) {
+	u.cancel(err)
+	u.pw.CloseWithError(err)
+}
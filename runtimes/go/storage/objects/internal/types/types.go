@@ -0,0 +1,398 @@
+// Package types defines the provider-agnostic data types shared between the
+// public objects API and the cloud-specific bucket implementations.
+package types
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"time"
+)
+
+// CloudObject is the name of an object within a bucket.
+type CloudObject string
+
+func (o CloudObject) String() string { return string(o) }
+
+var (
+	// ErrObjectNotExist is returned when the object does not exist.
+	ErrObjectNotExist = errors.New("object does not exist")
+
+	// ErrPreconditionFailed is returned when a precondition for an operation was not met.
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrNotSupported is returned when an operation is not supported by the
+	// bucket's cloud provider, as opposed to failing due to runtime
+	// conditions. Callers that can react to this structurally (rather than
+	// just surfacing the error) should check for it with errors.Is.
+	ErrNotSupported = errors.New("not supported by this cloud provider")
+)
+
+// BucketImpl is the interface that must be implemented by a cloud provider
+// for it to be usable as the implementation behind a Bucket.
+type BucketImpl interface {
+	Download(data DownloadData) (Downloader, error)
+	Upload(data UploadData) (Uploader, error)
+	List(data ListData) iter.Seq2[*ListEntry, error]
+	ListPaged(data ListData) (*ListPage, error)
+	Remove(data RemoveData) error
+	Attrs(data AttrsData) (*ObjectAttrs, error)
+	SignedURL(data SignedURLData) (*SignedURL, error)
+	Copy(data CopyData) (*ObjectAttrs, error)
+	Compose(data ComposeData) (*ObjectAttrs, error)
+	UpdateAttrs(data UpdateAttrsData) (*ObjectAttrs, error)
+
+	EnableVersioning(ctx context.Context) error
+	SuspendVersioning(ctx context.Context) error
+	VersioningStatus(ctx context.Context) (*BucketVersioningStatus, error)
+
+	Subscribe(data SubscribeData) error
+}
+
+// BucketVersioningStatus describes whether a bucket's versioning is enabled.
+type BucketVersioningStatus struct {
+	Enabled bool
+}
+
+// Downloader is returned by BucketImpl.Download and streams the object's contents.
+type Downloader interface {
+	io.Reader
+	io.Closer
+}
+
+// Uploader is returned by BucketImpl.Upload and streams the object's contents
+// to the bucket.
+type Uploader interface {
+	io.Writer
+
+	// Complete completes the upload and returns the resulting object's attributes.
+	Complete() (*ObjectAttrs, error)
+
+	// Abort aborts the upload, discarding any data written so far.
+	Abort(err error)
+}
+
+// Preconditions are the available preconditions for an operation.
+type Preconditions struct {
+	// NotExists specifies that the object must not exist prior to the operation.
+	NotExists bool
+
+	// GenerationMatch specifies that the operation should only proceed if the
+	// object's current generation (version) matches the given value.
+	GenerationMatch string
+
+	// GenerationNotMatch specifies that the operation should only proceed if
+	// the object's current generation (version) does not match the given value.
+	GenerationNotMatch string
+
+	// MetagenerationMatch specifies that the operation should only proceed if
+	// the object's metageneration matches the given value.
+	MetagenerationMatch int64
+
+	// IfMatchETag specifies that the operation should only proceed if the
+	// object's ETag matches the given value.
+	IfMatchETag string
+
+	// IfNoneMatchETag specifies that the operation should only proceed if the
+	// object's ETag does not match the given value.
+	IfNoneMatchETag string
+}
+
+// UploadAttrs specifies additional object attributes to set during upload.
+type UploadAttrs struct {
+	// ContentType specifies the content type of the object.
+	ContentType string
+
+	// Metadata specifies user-provided metadata to associate with the object.
+	Metadata map[string]string
+
+	// CacheControl specifies the Cache-Control header to serve the object with.
+	CacheControl string
+
+	// ContentEncoding specifies the Content-Encoding header to serve the object with.
+	ContentEncoding string
+
+	// ContentDisposition specifies the Content-Disposition header to serve the object with.
+	ContentDisposition string
+
+	// ContentLanguage specifies the Content-Language header to serve the object with.
+	ContentLanguage string
+
+	// CRC32C, if set, is the CRC32C checksum (using the Castagnoli polynomial)
+	// of the object's content, verified by the provider once the upload completes.
+	CRC32C *uint32
+
+	// MD5, if set, is the MD5 digest of the object's content, verified by the
+	// provider once the upload completes.
+	MD5 []byte
+
+	// StorageClass specifies the storage class to store the object with.
+	StorageClass string
+
+	// EncryptionKey, if set, is a customer-supplied AES-256 key used to
+	// encrypt the object's content.
+	EncryptionKey []byte
+}
+
+// DownloadData describes a Download operation.
+type DownloadData struct {
+	Ctx     context.Context
+	Object  CloudObject
+	Version string
+	Pre     Preconditions
+
+	// EncryptionKey, if set, is the customer-supplied AES-256 key the object
+	// was uploaded with. Required to read an object uploaded with
+	// UploadAttrs.EncryptionKey set.
+	EncryptionKey []byte
+}
+
+// UploadData describes an Upload operation.
+type UploadData struct {
+	Ctx    context.Context
+	Object CloudObject
+	Attrs  UploadAttrs
+	Pre    Preconditions
+
+	// SendCRC32C requests that the provider's client library compute a
+	// CRC32C checksum as the object is streamed and verify it server-side
+	// once the upload completes.
+	SendCRC32C bool
+}
+
+// ListData describes a List operation.
+type ListData struct {
+	Ctx    context.Context
+	Prefix string
+	Limit  *int64
+
+	// AllVersions specifies that every version of every object should be
+	// emitted, rather than just the current (latest, non-deleted) one.
+	AllVersions bool
+
+	// Delimiter, if set, groups object names after the prefix up to the next
+	// occurrence of the delimiter into a single common prefix, returned via
+	// ListPage.Prefixes instead of being descended into.
+	Delimiter string
+
+	// StartOffset, if set, filters results to objects whose name is greater
+	// than or equal to it, lexicographically.
+	StartOffset string
+
+	// EndOffset, if set, filters results to objects whose name is less than
+	// it, lexicographically.
+	EndOffset string
+
+	// PageToken resumes listing from the point a previous ListPaged call left
+	// off, as returned in ListPage.NextPageToken. Only used by ListPaged.
+	PageToken string
+
+	// PageSize caps the number of entries returned by a single ListPaged
+	// call. Zero means the provider's default page size. Only used by
+	// ListPaged.
+	PageSize int
+}
+
+// ListPage is a single page of results from a ListPaged call.
+type ListPage struct {
+	// Entries are the objects found on this page.
+	Entries []*ListEntry
+
+	// Prefixes are the common prefixes found on this page, when ListData.Delimiter is set.
+	Prefixes []string
+
+	// NextPageToken resumes listing after this page. Empty if there are no more pages.
+	NextPageToken string
+}
+
+// RemoveData describes a Remove operation.
+type RemoveData struct {
+	Ctx     context.Context
+	Object  CloudObject
+	Version string
+	Pre     Preconditions
+}
+
+// AttrsData describes an Attrs operation.
+type AttrsData struct {
+	Ctx     context.Context
+	Object  CloudObject
+	Version string
+
+	// EncryptionKey, if set, is the customer-supplied AES-256 key the object
+	// was uploaded with. Required to read the attributes of an object
+	// uploaded with UploadAttrs.EncryptionKey set.
+	EncryptionKey []byte
+}
+
+// ObjectAttrs describes the attributes of an object in a bucket.
+type ObjectAttrs struct {
+	Object      CloudObject
+	Version     string
+	ContentType string
+	Size        int64
+	ETag        string
+
+	Metadata           map[string]string
+	CacheControl       string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CRC32C             *uint32
+	MD5                []byte
+	StorageClass       string
+}
+
+// ListEntry describes a single object returned from a List operation.
+type ListEntry struct {
+	Object  CloudObject
+	Version string
+	Size    int64
+	ETag    string
+
+	// IsLatest reports whether this is the current version of the object.
+	// Only meaningful when the List operation requested all versions.
+	IsLatest bool
+
+	// DeletedAt is set if this version of the object has been deleted,
+	// i.e. it's a noncurrent version retained by a versioned bucket.
+	DeletedAt *time.Time
+
+	Metadata           map[string]string
+	CacheControl       string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CRC32C             *uint32
+	MD5                []byte
+	StorageClass       string
+}
+
+// SignedURLData describes a request to generate a pre-signed URL for an object.
+type SignedURLData struct {
+	Ctx context.Context
+
+	Object  CloudObject
+	Version string
+
+	// Method is the HTTP method the signed URL grants access to, e.g. "GET", "PUT", "DELETE".
+	Method string
+
+	// TTL is how long the signed URL remains valid for.
+	TTL time.Duration
+
+	// ContentType, if set, requires the request made using the signed URL
+	// to carry a matching Content-Type header.
+	ContentType string
+
+	// ContentMD5, if set, requires the request made using the signed URL
+	// to carry a matching Content-MD5 header.
+	ContentMD5 []byte
+
+	// ResponseHeaders are additional headers the object storage service should
+	// include in its response when the signed URL is used for a GET/HEAD request.
+	ResponseHeaders map[string][]string
+}
+
+// SignedURL describes a generated pre-signed URL and its expiration.
+type SignedURL struct {
+	// URL is the signed URL.
+	URL string
+
+	// ExpiresAt is when the signed URL expires.
+	ExpiresAt time.Time
+}
+
+// CopyData describes a Copy operation.
+type CopyData struct {
+	Ctx context.Context
+
+	Src        CloudObject
+	SrcVersion string
+
+	// SrcBucket is the bucket the source object resides in, if different
+	// from the destination bucket. Nil means the same bucket.
+	SrcBucket BucketImpl
+
+	Dst CloudObject
+	Pre Preconditions
+
+	// Metadata, if non-nil, replaces the destination object's metadata
+	// rather than inheriting it from the source object.
+	Metadata *UploadAttrs
+}
+
+// UpdateAttrsData describes an UpdateAttrs operation.
+type UpdateAttrsData struct {
+	Ctx     context.Context
+	Object  CloudObject
+	Version string
+	Pre     Preconditions
+
+	// EncryptionKey, if set, is the customer-supplied AES-256 key the object
+	// was uploaded with. Required to update the attributes of an object
+	// uploaded with UploadAttrs.EncryptionKey set.
+	EncryptionKey []byte
+
+	// Attrs specifies the attributes to set on the object. Fields that the
+	// provider cannot update in place (e.g. StorageClass, CRC32C, MD5,
+	// EncryptionKey) are ignored.
+	Attrs UploadAttrs
+}
+
+// ComposeData describes a Compose operation.
+type ComposeData struct {
+	Ctx context.Context
+
+	Sources []CloudObject
+	Dst     CloudObject
+	Pre     Preconditions
+
+	// Metadata, if non-nil, replaces the destination object's metadata
+	// rather than inheriting it from the last source object.
+	Metadata *UploadAttrs
+}
+
+// EventType identifies the kind of change to an object that a bucket
+// notification fires for.
+type EventType string
+
+const (
+	// EventTypeObjectFinalized fires when a new object is created, or an
+	// existing object's content or metadata is replaced.
+	EventTypeObjectFinalized EventType = "finalized"
+
+	// EventTypeObjectDeleted fires when an object is permanently deleted, or,
+	// in a versioned bucket, when a version is removed.
+	EventTypeObjectDeleted EventType = "deleted"
+)
+
+// Event describes a single change to an object that a subscription was
+// notified about.
+type Event struct {
+	Object    CloudObject
+	Version   string
+	Size      int64
+	EventType EventType
+	EventTime time.Time
+}
+
+// SubscribeData describes a request to register a bucket notification
+// subscription.
+type SubscribeData struct {
+	// Name identifies the subscription. Must be unique within the bucket, as
+	// it's used to name the underlying cloud notification resource.
+	Name string
+
+	// Events filters which kinds of changes the subscription is notified
+	// about. If empty, the subscription receives every event type.
+	Events []EventType
+
+	// Prefix filters the subscription to only objects whose name starts with
+	// the given prefix. If empty, all objects in the bucket match.
+	Prefix string
+
+	// Handler is called for each matching event.
+	Handler func(ctx context.Context, event *Event) error
+}
@@ -0,0 +1,67 @@
+package objects
+
+import (
+	"context"
+
+	"encore.dev/appruntime/exported/config"
+	"encore.dev/storage/objects/internal/providers/gcs"
+	"encore.dev/storage/objects/internal/providers/s3"
+	"encore.dev/storage/objects/internal/types"
+)
+
+// provider is implemented by each cloud-specific bucket provider package,
+// allowing the Manager to pick the right one for a given bucket's configuration.
+type provider interface {
+	ProviderName() string
+	Matches(cfg *config.BucketProvider) bool
+	NewBucket(provider *config.BucketProvider, runtimeCfg *config.Bucket) types.BucketImpl
+}
+
+// Manager manages the object storage buckets configured for this running Encore application,
+// resolving each one to the appropriate cloud provider implementation.
+//
+//publicapigen:keep
+type Manager struct {
+	ctx       context.Context
+	cfg       *config.Runtime
+	providers []provider
+}
+
+// NewManager constructs a new Manager for the given runtime configuration.
+//
+//publicapigen:keep
+func NewManager(ctx context.Context, cfg *config.Runtime) *Manager {
+	return &Manager{
+		ctx: ctx,
+		cfg: cfg,
+		providers: []provider{
+			gcs.NewManager(ctx, cfg),
+			s3.NewManager(ctx, cfg),
+		},
+	}
+}
+
+// Singleton is the Manager used by buckets declared via NewBucket.
+// It's initialized by the Encore runtime at application startup.
+//
+//publicapigen:keep
+var Singleton *Manager
+
+func getImpl(name string) types.BucketImpl {
+	return Singleton.getBucketImpl(name)
+}
+
+func (mgr *Manager) getBucketImpl(name string) types.BucketImpl {
+	for _, b := range mgr.cfg.Buckets {
+		if b.Name != name {
+			continue
+		}
+		bp := mgr.cfg.BucketProviders[b.ProviderID]
+		for _, p := range mgr.providers {
+			if p.Matches(bp) {
+				return p.NewBucket(bp, b)
+			}
+		}
+	}
+	panic("encore: unknown bucket: " + name)
+}
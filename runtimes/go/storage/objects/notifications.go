@@ -0,0 +1,104 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"encore.dev/storage/objects/internal/types"
+)
+
+// EventType identifies the kind of change to an object that a bucket
+// notification fires for.
+type EventType = types.EventType
+
+const (
+	// ObjectFinalized fires when a new object is created, or an existing
+	// object's content or metadata is replaced.
+	ObjectFinalized = types.EventTypeObjectFinalized
+
+	// ObjectDeleted fires when an object is permanently deleted, or, in a
+	// versioned bucket, when a version is removed.
+	ObjectDeleted = types.EventTypeObjectDeleted
+)
+
+// Event describes a single change to an object that a subscription was
+// notified about.
+type Event struct {
+	// Object is the name of the object that changed.
+	Object string
+	// Version is the version of the object, if the bucket is versioned.
+	Version string
+	// Size is the size of the object, in bytes, as reported by the notification.
+	Size int64
+	// EventType is the kind of change that occurred.
+	EventType EventType
+	// EventTime is when the change occurred.
+	EventTime time.Time
+}
+
+// SubscriptionConfig is the configuration for a bucket notification Subscription.
+type SubscriptionConfig struct {
+	// Events filters which kinds of changes the subscription is notified about.
+	// If empty, the subscription receives every event type.
+	Events []EventType
+
+	// Prefix filters the subscription to only objects whose name starts with
+	// the given prefix. If empty, all objects in the bucket match.
+	Prefix string
+
+	// Handler is called for each matching event.
+	Handler func(ctx context.Context, event *Event) error
+}
+
+// Subscription represents a registered bucket notification subscription.
+//
+// See NewSubscription for more information on how to declare a Subscription.
+//
+//publicapigen:keep
+type Subscription struct {
+	name string
+	b    *Bucket
+}
+
+// NewSubscription declares a subscription to a bucket's object notifications,
+// invoking cfg.Handler for every matching event.
+//
+// The name must be unique for the given bucket, as it's used to identify the
+// underlying cloud notification resource.
+//
+// NewSubscription must be called from within a package level variable
+// declaration, as Encore's static analysis needs to see the declaration at
+// compile time.
+func NewSubscription(b *Bucket, name string, cfg SubscriptionConfig) *Subscription {
+	err := b.impl.Subscribe(types.SubscribeData{
+		Name:   name,
+		Events: cfg.Events,
+		Prefix: cfg.Prefix,
+		Handler: func(ctx context.Context, e *types.Event) error {
+			return cfg.Handler(ctx, &Event{
+				Object:    e.Object.String(),
+				Version:   e.Version,
+				Size:      e.Size,
+				EventType: e.EventType,
+				EventTime: e.EventTime,
+			})
+		},
+	})
+	if errors.Is(err, types.ErrNotSupported) {
+		// Ideally Encore's static analysis would reject this at compile
+		// time, the same way it rejects other invalid resource
+		// declarations; until every provider supports notifications, fail
+		// loudly but don't take down an otherwise-valid app that merely
+		// declared a subscription its bucket's cloud provider can't back.
+		log.Printf("objects: subscription %q on bucket %q is disabled: %s", name, b.name, err)
+		return &Subscription{name: name, b: b}
+	}
+	if err != nil {
+		panic(fmt.Sprintf("objects: failed to register subscription %q on bucket %q: %s", name, b.name, err))
+	}
+
+	return &Subscription{name: name, b: b}
+}
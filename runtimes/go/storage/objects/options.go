@@ -33,16 +33,56 @@ func (o withVersionOption) attrsOption() {}
 //publicapigen:keep
 func (o withVersionOption) existsOption() {}
 
-func (o withVersionOption) applyDownload(opts *downloadOptions) { opts.version = o.version }
-func (o withVersionOption) applyRemove(opts *removeOptions)     { opts.version = o.version }
-func (o withVersionOption) applyAttrs(opts *attrsOptions)       { opts.version = o.version }
-func (o withVersionOption) applyExists(opts *existsOptions)     { opts.version = o.version }
+//publicapigen:keep
+func (o withVersionOption) signedURLOption() {}
+
+//publicapigen:keep
+func (o withVersionOption) updateAttrsOption() {}
+
+func (o withVersionOption) applyDownload(opts *downloadOptions)       { opts.version = o.version }
+func (o withVersionOption) applyRemove(opts *removeOptions)           { opts.version = o.version }
+func (o withVersionOption) applyAttrs(opts *attrsOptions)             { opts.version = o.version }
+func (o withVersionOption) applyExists(opts *existsOptions)           { opts.version = o.version }
+func (o withVersionOption) applySignedURL(opts *signedURLOptions)     { opts.version = o.version }
+func (o withVersionOption) applyUpdateAttrs(opts *updateAttrsOptions) { opts.version = o.version }
 
 //publicapigen:keep
 type downloadOptions struct {
-	version string
+	version       string
+	pre           Preconditions
+	encryptionKey []byte
+}
+
+// WithEncryptionKey is a DownloadOption, AttrsOption, ExistsOption, and
+// UpdateAttrsOption that supplies the customer-supplied AES-256 key an
+// object was uploaded with, via WithUploadAttrs' UploadAttrs.EncryptionKey.
+// It's required to read or update the attributes of such an object.
+func WithEncryptionKey(key []byte) withEncryptionKeyOption {
+	return withEncryptionKeyOption{key: key}
 }
 
+//publicapigen:keep
+type withEncryptionKeyOption struct {
+	key []byte
+}
+
+//publicapigen:keep
+func (o withEncryptionKeyOption) downloadOption() {}
+
+//publicapigen:keep
+func (o withEncryptionKeyOption) attrsOption() {}
+
+//publicapigen:keep
+func (o withEncryptionKeyOption) existsOption() {}
+
+//publicapigen:keep
+func (o withEncryptionKeyOption) updateAttrsOption() {}
+
+func (o withEncryptionKeyOption) applyDownload(opts *downloadOptions)       { opts.encryptionKey = o.key }
+func (o withEncryptionKeyOption) applyAttrs(opts *attrsOptions)             { opts.encryptionKey = o.key }
+func (o withEncryptionKeyOption) applyExists(opts *existsOptions)           { opts.encryptionKey = o.key }
+func (o withEncryptionKeyOption) applyUpdateAttrs(opts *updateAttrsOptions) { opts.encryptionKey = o.key }
+
 // UploadOption describes available options for the Upload operation.
 type UploadOption interface {
 	uploadOption()
@@ -50,16 +90,42 @@ type UploadOption interface {
 	applyUpload(*uploadOptions)
 }
 
-// WithPreconditions is an UploadOption for only uploading an object
-// if certain preconditions are met.
+// WithPreconditions is an UploadOption, DownloadOption, RemoveOption, and
+// UpdateAttrsOption that only performs the operation if the given
+// preconditions on the object are met.
 func WithPreconditions(pre Preconditions) withPreconditionsOption {
 	return withPreconditionsOption{pre: pre}
 }
 
-// Preconditions are the available preconditions for an upload operation.
+// Preconditions are the available preconditions for an operation.
 type Preconditions struct {
-	// NotExists specifies that the object must not exist prior to uploading.
+	// NotExists specifies that the object must not exist prior to the
+	// operation. Honored by Upload, Copy, and Compose on all providers;
+	// support on Download, Remove, and UpdateAttrs varies by provider (see
+	// the provider package doc comments for exceptions).
 	NotExists bool
+
+	// GenerationMatch specifies that the operation should only proceed if the
+	// object's current version matches the given value, as returned in
+	// ObjectAttrs.Version or ListEntry.Version.
+	GenerationMatch string
+
+	// GenerationNotMatch specifies that the operation should only proceed if
+	// the object's current version does not match the given value.
+	GenerationNotMatch string
+
+	// MetagenerationMatch specifies that the operation should only proceed if
+	// the object's metageneration matches the given value. Only supported by
+	// some providers.
+	MetagenerationMatch int64
+
+	// IfMatchETag specifies that the operation should only proceed if the
+	// object's ETag matches the given value.
+	IfMatchETag string
+
+	// IfNoneMatchETag specifies that the operation should only proceed if the
+	// object's ETag does not match the given value.
+	IfNoneMatchETag string
 }
 
 //publicapigen:keep
@@ -70,14 +136,54 @@ type withPreconditionsOption struct {
 //publicapigen:keep
 func (o withPreconditionsOption) uploadOption() {}
 
-func (o withPreconditionsOption) applyUpload(opts *uploadOptions) {
-	opts.pre = o.pre
-}
+//publicapigen:keep
+func (o withPreconditionsOption) downloadOption() {}
+
+//publicapigen:keep
+func (o withPreconditionsOption) removeOption() {}
+
+//publicapigen:keep
+func (o withPreconditionsOption) updateAttrsOption() {}
+
+func (o withPreconditionsOption) applyUpload(opts *uploadOptions)           { opts.pre = o.pre }
+func (o withPreconditionsOption) applyDownload(opts *downloadOptions)       { opts.pre = o.pre }
+func (o withPreconditionsOption) applyRemove(opts *removeOptions)           { opts.pre = o.pre }
+func (o withPreconditionsOption) applyUpdateAttrs(opts *updateAttrsOptions) { opts.pre = o.pre }
 
 // UploadAttrs specifies additional object attributes to set during upload.
 type UploadAttrs struct {
 	// ContentType specifies the content type of the object.
 	ContentType string
+
+	// Metadata specifies user-provided metadata to associate with the object.
+	Metadata map[string]string
+
+	// CacheControl specifies the Cache-Control header to serve the object with.
+	CacheControl string
+
+	// ContentEncoding specifies the Content-Encoding header to serve the object with.
+	ContentEncoding string
+
+	// ContentDisposition specifies the Content-Disposition header to serve the object with.
+	ContentDisposition string
+
+	// ContentLanguage specifies the Content-Language header to serve the object with.
+	ContentLanguage string
+
+	// CRC32C, if set, is the CRC32C checksum (using the Castagnoli polynomial)
+	// of the object's content, verified by the provider once the upload completes.
+	CRC32C *uint32
+
+	// MD5, if set, is the MD5 digest of the object's content, verified by the
+	// provider once the upload completes.
+	MD5 []byte
+
+	// StorageClass specifies the storage class to store the object with.
+	StorageClass string
+
+	// EncryptionKey, if set, is a customer-supplied AES-256 key used to
+	// encrypt the object's content.
+	EncryptionKey []byte
 }
 
 // WithUploadAttrs is an UploadOption for specifying additional object attributes
@@ -96,13 +202,48 @@ func (o withUploadAttrsOption) uploadOption() {}
 
 func (o withUploadAttrsOption) applyUpload(opts *uploadOptions) {
 	opts.attrs = types.UploadAttrs{
-		ContentType: o.attrs.ContentType,
+		ContentType:        o.attrs.ContentType,
+		Metadata:           o.attrs.Metadata,
+		CacheControl:       o.attrs.CacheControl,
+		ContentEncoding:    o.attrs.ContentEncoding,
+		ContentDisposition: o.attrs.ContentDisposition,
+		ContentLanguage:    o.attrs.ContentLanguage,
+		CRC32C:             o.attrs.CRC32C,
+		MD5:                o.attrs.MD5,
+		StorageClass:       o.attrs.StorageClass,
+		EncryptionKey:      o.attrs.EncryptionKey,
+	}
+}
+
+// WithChecksumValidation is an UploadOption that enables end-to-end integrity
+// checking of the upload: when crc32c is true, the client library computes a
+// CRC32C checksum as the object is streamed and the provider verifies it once
+// the upload completes; when md5 is non-nil, it's the expected MD5 digest of
+// the object's content, verified the same way.
+func WithChecksumValidation(crc32c bool, md5 []byte) withChecksumValidationOption {
+	return withChecksumValidationOption{crc32c: crc32c, md5: md5}
+}
+
+//publicapigen:keep
+type withChecksumValidationOption struct {
+	crc32c bool
+	md5    []byte
+}
+
+//publicapigen:keep
+func (o withChecksumValidationOption) uploadOption() {}
+
+func (o withChecksumValidationOption) applyUpload(opts *uploadOptions) {
+	opts.sendCRC32C = o.crc32c
+	if o.md5 != nil {
+		opts.attrs.MD5 = o.md5
 	}
 }
 
 type uploadOptions struct {
-	attrs types.UploadAttrs
-	pre   Preconditions
+	attrs      types.UploadAttrs
+	pre        Preconditions
+	sendCRC32C bool
 }
 
 // ListOption describes available options for the List operation.
@@ -113,7 +254,131 @@ type ListOption interface {
 	applyList(*listOptions)
 }
 
-type listOptions struct{}
+// WithAllVersions causes List to emit every version of every object,
+// rather than just the current (latest, non-deleted) one. Only meaningful
+// for versioned buckets.
+func WithAllVersions() withAllVersionsOption {
+	return withAllVersionsOption{}
+}
+
+//publicapigen:keep
+type withAllVersionsOption struct{}
+
+//publicapigen:keep
+func (o withAllVersionsOption) listOption() {}
+
+//publicapigen:keep
+func (o withAllVersionsOption) listPageOption() {}
+
+func (o withAllVersionsOption) applyList(opts *listOptions)         { opts.allVersions = true }
+func (o withAllVersionsOption) applyListPage(opts *listPageOptions) { opts.allVersions = true }
+
+// WithDelimiter is a ListOption and ListPageOption that groups object names
+// after the prefix up to the next occurrence of delimiter into a single
+// common prefix, rather than descending into it. When using List, common
+// prefixes are skipped; use ListPaged to also get them back, via
+// ListPage.Prefixes.
+func WithDelimiter(delimiter string) withDelimiterOption {
+	return withDelimiterOption{delimiter: delimiter}
+}
+
+//publicapigen:keep
+type withDelimiterOption struct {
+	delimiter string
+}
+
+//publicapigen:keep
+func (o withDelimiterOption) listOption() {}
+
+//publicapigen:keep
+func (o withDelimiterOption) listPageOption() {}
+
+func (o withDelimiterOption) applyList(opts *listOptions)         { opts.delimiter = o.delimiter }
+func (o withDelimiterOption) applyListPage(opts *listPageOptions) { opts.delimiter = o.delimiter }
+
+// WithOffsets is a ListOption and ListPageOption that restricts results to
+// object names that are lexicographically greater than or equal to
+// startOffset and, if endOffset is non-empty, less than endOffset.
+func WithOffsets(startOffset, endOffset string) withOffsetsOption {
+	return withOffsetsOption{startOffset: startOffset, endOffset: endOffset}
+}
+
+//publicapigen:keep
+type withOffsetsOption struct {
+	startOffset string
+	endOffset   string
+}
+
+//publicapigen:keep
+func (o withOffsetsOption) listOption() {}
+
+//publicapigen:keep
+func (o withOffsetsOption) listPageOption() {}
+
+func (o withOffsetsOption) applyList(opts *listOptions) {
+	opts.startOffset = o.startOffset
+	opts.endOffset = o.endOffset
+}
+
+func (o withOffsetsOption) applyListPage(opts *listPageOptions) {
+	opts.startOffset = o.startOffset
+	opts.endOffset = o.endOffset
+}
+
+type listOptions struct {
+	allVersions bool
+	delimiter   string
+	startOffset string
+	endOffset   string
+}
+
+// ListPageOption describes available options for the ListPaged operation.
+type ListPageOption interface {
+	//publicapigen:keep
+	listPageOption()
+
+	applyListPage(*listPageOptions)
+}
+
+// WithPageToken resumes a ListPaged call from the point a previous call left
+// off, as returned in ListPage.NextPageToken.
+func WithPageToken(token string) withPageTokenOption {
+	return withPageTokenOption{token: token}
+}
+
+//publicapigen:keep
+type withPageTokenOption struct {
+	token string
+}
+
+//publicapigen:keep
+func (o withPageTokenOption) listPageOption() {}
+
+func (o withPageTokenOption) applyListPage(opts *listPageOptions) { opts.pageToken = o.token }
+
+// WithPageSize caps the number of entries a single ListPaged call returns.
+func WithPageSize(size int) withPageSizeOption {
+	return withPageSizeOption{size: size}
+}
+
+//publicapigen:keep
+type withPageSizeOption struct {
+	size int
+}
+
+//publicapigen:keep
+func (o withPageSizeOption) listPageOption() {}
+
+func (o withPageSizeOption) applyListPage(opts *listPageOptions) { opts.pageSize = o.size }
+
+type listPageOptions struct {
+	allVersions bool
+	delimiter   string
+	startOffset string
+	endOffset   string
+	pageToken   string
+	pageSize    int
+}
 
 // RemoveOption describes available options for the Remove operation.
 type RemoveOption interface {
@@ -125,6 +390,7 @@ type RemoveOption interface {
 
 type removeOptions struct {
 	version string
+	pre     Preconditions
 }
 
 // AttrsOption describes available options for the Attrs operation.
@@ -136,7 +402,8 @@ type AttrsOption interface {
 }
 
 type attrsOptions struct {
-	version string
+	version       string
+	encryptionKey []byte
 }
 
 // ExistsOption describes available options for the Exists operation.
@@ -148,5 +415,216 @@ type ExistsOption interface {
 }
 
 type existsOptions struct {
+	version       string
+	encryptionKey []byte
+}
+
+// UpdateAttrsOption describes available options for the UpdateAttrs operation.
+type UpdateAttrsOption interface {
+	//publicapigen:keep
+	updateAttrsOption()
+
+	applyUpdateAttrs(*updateAttrsOptions)
+}
+
+type updateAttrsOptions struct {
+	version       string
+	pre           Preconditions
+	encryptionKey []byte
+}
+
+// SignedURLOption describes available options for generating a signed URL.
+type SignedURLOption interface {
+	//publicapigen:keep
+	signedURLOption()
+
+	applySignedURL(*signedURLOptions)
+}
+
+// WithSignedURLContentType requires that requests made using the signed URL
+// carry a matching Content-Type header. Only applies to upload URLs.
+func WithSignedURLContentType(contentType string) withSignedURLContentTypeOption {
+	return withSignedURLContentTypeOption{contentType: contentType}
+}
+
+//publicapigen:keep
+type withSignedURLContentTypeOption struct {
+	contentType string
+}
+
+//publicapigen:keep
+func (o withSignedURLContentTypeOption) signedURLOption() {}
+
+func (o withSignedURLContentTypeOption) applySignedURL(opts *signedURLOptions) {
+	opts.contentType = o.contentType
+}
+
+// WithSignedURLContentMD5 requires that requests made using the signed URL
+// carry a matching Content-MD5 header. Only applies to upload URLs.
+func WithSignedURLContentMD5(md5 []byte) withSignedURLContentMD5Option {
+	return withSignedURLContentMD5Option{md5: md5}
+}
+
+//publicapigen:keep
+type withSignedURLContentMD5Option struct {
+	md5 []byte
+}
+
+//publicapigen:keep
+func (o withSignedURLContentMD5Option) signedURLOption() {}
+
+func (o withSignedURLContentMD5Option) applySignedURL(opts *signedURLOptions) {
+	opts.contentMD5 = o.md5
+}
+
+// WithSignedURLResponseHeader sets a header that the object storage service
+// should include in its response when the signed URL is used to perform a
+// GET or HEAD request.
+func WithSignedURLResponseHeader(header string, values ...string) withSignedURLResponseHeaderOption {
+	return withSignedURLResponseHeaderOption{header: header, values: values}
+}
+
+//publicapigen:keep
+type withSignedURLResponseHeaderOption struct {
+	header string
+	values []string
+}
+
+//publicapigen:keep
+func (o withSignedURLResponseHeaderOption) signedURLOption() {}
+
+func (o withSignedURLResponseHeaderOption) applySignedURL(opts *signedURLOptions) {
+	if opts.responseHeaders == nil {
+		opts.responseHeaders = make(map[string][]string)
+	}
+	opts.responseHeaders[o.header] = append(opts.responseHeaders[o.header], o.values...)
+}
+
+type signedURLOptions struct {
+	version         string
+	contentType     string
+	contentMD5      []byte
+	responseHeaders map[string][]string
+}
+
+// CopyOption describes available options for the Copy operation.
+type CopyOption interface {
+	//publicapigen:keep
+	copyOption()
+
+	applyCopy(*copyOptions)
+}
+
+// ComposeOption describes available options for the Compose operation.
+type ComposeOption interface {
+	//publicapigen:keep
+	composeOption()
+
+	applyCompose(*composeOptions)
+}
+
+// WithSourceBucket is a CopyOption that specifies the bucket the source
+// object resides in, when copying an object across buckets. If not given,
+// the source object is assumed to reside in the destination bucket.
+func WithSourceBucket(b *Bucket) withSourceBucketOption {
+	return withSourceBucketOption{bucket: b}
+}
+
+//publicapigen:keep
+type withSourceBucketOption struct {
+	bucket *Bucket
+}
+
+//publicapigen:keep
+func (o withSourceBucketOption) copyOption() {}
+
+func (o withSourceBucketOption) applyCopy(opts *copyOptions) { opts.srcBucket = o.bucket }
+
+// WithSourceVersion is a CopyOption that specifies the version of the source
+// object to copy from.
+func WithSourceVersion(version string) withSourceVersionOption {
+	return withSourceVersionOption{version: version}
+}
+
+//publicapigen:keep
+type withSourceVersionOption struct {
 	version string
 }
+
+//publicapigen:keep
+func (o withSourceVersionOption) copyOption() {}
+
+func (o withSourceVersionOption) applyCopy(opts *copyOptions) { opts.srcVersion = o.version }
+
+// WithDestinationPreconditions is a CopyOption and ComposeOption that only
+// performs the operation if the given preconditions on the destination
+// object are met.
+func WithDestinationPreconditions(pre Preconditions) withDestinationPreconditionsOption {
+	return withDestinationPreconditionsOption{pre: pre}
+}
+
+//publicapigen:keep
+type withDestinationPreconditionsOption struct {
+	pre Preconditions
+}
+
+//publicapigen:keep
+func (o withDestinationPreconditionsOption) copyOption() {}
+
+//publicapigen:keep
+func (o withDestinationPreconditionsOption) composeOption() {}
+
+func (o withDestinationPreconditionsOption) applyCopy(opts *copyOptions)       { opts.pre = o.pre }
+func (o withDestinationPreconditionsOption) applyCompose(opts *composeOptions) { opts.pre = o.pre }
+
+// WithMetadataReplacement is a CopyOption and ComposeOption that replaces the
+// destination object's metadata with attrs, rather than inheriting it from
+// the source object(s).
+func WithMetadataReplacement(attrs UploadAttrs) withMetadataReplacementOption {
+	return withMetadataReplacementOption{attrs: attrs}
+}
+
+//publicapigen:keep
+type withMetadataReplacementOption struct {
+	attrs UploadAttrs
+}
+
+//publicapigen:keep
+func (o withMetadataReplacementOption) copyOption() {}
+
+//publicapigen:keep
+func (o withMetadataReplacementOption) composeOption() {}
+
+func (o withMetadataReplacementOption) applyCopy(opts *copyOptions) {
+	attrs := o.toUploadAttrs()
+	opts.metadata = &attrs
+}
+
+func (o withMetadataReplacementOption) applyCompose(opts *composeOptions) {
+	attrs := o.toUploadAttrs()
+	opts.metadata = &attrs
+}
+
+func (o withMetadataReplacementOption) toUploadAttrs() types.UploadAttrs {
+	return types.UploadAttrs{
+		ContentType:        o.attrs.ContentType,
+		Metadata:           o.attrs.Metadata,
+		CacheControl:       o.attrs.CacheControl,
+		ContentEncoding:    o.attrs.ContentEncoding,
+		ContentDisposition: o.attrs.ContentDisposition,
+		ContentLanguage:    o.attrs.ContentLanguage,
+		StorageClass:       o.attrs.StorageClass,
+	}
+}
+
+type copyOptions struct {
+	srcBucket  *Bucket
+	srcVersion string
+	pre        Preconditions
+	metadata   *types.UploadAttrs
+}
+
+type composeOptions struct {
+	pre      Preconditions
+	metadata *types.UploadAttrs
+}
@@ -0,0 +1,103 @@
+package objects
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"iter"
+	"testing"
+
+	"encore.dev/storage/objects/internal/types"
+)
+
+// fakeImpl is a types.BucketImpl that just records the *Data struct passed
+// to each method, so tests can assert that options map into it correctly.
+type fakeImpl struct {
+	downloadData    types.DownloadData
+	attrsData       types.AttrsData
+	updateAttrsData types.UpdateAttrsData
+}
+
+func (f *fakeImpl) Download(data types.DownloadData) (types.Downloader, error) {
+	f.downloadData = data
+	return io.NopCloser(bytes.NewReader(nil)), nil
+}
+
+func (f *fakeImpl) Upload(data types.UploadData) (types.Uploader, error) { return nil, nil }
+
+func (f *fakeImpl) List(data types.ListData) iter.Seq2[*types.ListEntry, error] {
+	return func(yield func(*types.ListEntry, error) bool) {}
+}
+
+func (f *fakeImpl) ListPaged(data types.ListData) (*types.ListPage, error) { return &types.ListPage{}, nil }
+
+func (f *fakeImpl) Remove(data types.RemoveData) error { return nil }
+
+func (f *fakeImpl) Attrs(data types.AttrsData) (*types.ObjectAttrs, error) {
+	f.attrsData = data
+	return &types.ObjectAttrs{Object: data.Object}, nil
+}
+
+func (f *fakeImpl) SignedURL(data types.SignedURLData) (*types.SignedURL, error) { return &types.SignedURL{}, nil }
+
+func (f *fakeImpl) Copy(data types.CopyData) (*types.ObjectAttrs, error) { return nil, nil }
+
+func (f *fakeImpl) Compose(data types.ComposeData) (*types.ObjectAttrs, error) { return nil, nil }
+
+func (f *fakeImpl) UpdateAttrs(data types.UpdateAttrsData) (*types.ObjectAttrs, error) {
+	f.updateAttrsData = data
+	return &types.ObjectAttrs{Object: data.Object}, nil
+}
+
+func (f *fakeImpl) EnableVersioning(ctx context.Context) error  { return nil }
+func (f *fakeImpl) SuspendVersioning(ctx context.Context) error { return nil }
+func (f *fakeImpl) VersioningStatus(ctx context.Context) (*types.BucketVersioningStatus, error) {
+	return &types.BucketVersioningStatus{}, nil
+}
+
+func (f *fakeImpl) Subscribe(data types.SubscribeData) error { return nil }
+
+func TestWithEncryptionKeyThreading(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	impl := &fakeImpl{}
+	b := &Bucket{name: "test", impl: impl}
+
+	if _, err := b.Download(context.Background(), "obj", WithEncryptionKey(key)); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if !bytes.Equal(impl.downloadData.EncryptionKey, key) {
+		t.Errorf("DownloadData.EncryptionKey = %x, want %x", impl.downloadData.EncryptionKey, key)
+	}
+
+	if _, err := b.Attrs(context.Background(), "obj", WithEncryptionKey(key)); err != nil {
+		t.Fatalf("Attrs: %v", err)
+	}
+	if !bytes.Equal(impl.attrsData.EncryptionKey, key) {
+		t.Errorf("AttrsData.EncryptionKey = %x, want %x", impl.attrsData.EncryptionKey, key)
+	}
+
+	if _, err := b.Exists(context.Background(), "obj", WithEncryptionKey(key)); err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	if !bytes.Equal(impl.attrsData.EncryptionKey, key) {
+		t.Errorf("Exists AttrsData.EncryptionKey = %x, want %x", impl.attrsData.EncryptionKey, key)
+	}
+
+	if _, err := b.UpdateAttrs(context.Background(), "obj", UploadAttrs{}, WithEncryptionKey(key)); err != nil {
+		t.Fatalf("UpdateAttrs: %v", err)
+	}
+	if !bytes.Equal(impl.updateAttrsData.EncryptionKey, key) {
+		t.Errorf("UpdateAttrsData.EncryptionKey = %x, want %x", impl.updateAttrsData.EncryptionKey, key)
+	}
+}
+
+func TestMapPreconditionsNotExists(t *testing.T) {
+	pre := mapPreconditions(Preconditions{NotExists: true, IfMatchETag: "abc"})
+	if !pre.NotExists {
+		t.Error("NotExists = false, want true")
+	}
+	if pre.IfMatchETag != "abc" {
+		t.Errorf("IfMatchETag = %q, want %q", pre.IfMatchETag, "abc")
+	}
+}